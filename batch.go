@@ -0,0 +1,131 @@
+package reflection
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// ValidateStructSlice validates the struct rows of a slice concurrently
+// using ValidateStructFieldsWithOptions with a pool of workers goroutines.
+// See ValidateStructSeq.
+//
+// Example:
+//
+//	type Row struct {
+//	    Email string `json:"email" validate:"required,email"`
+//	}
+//
+//	fieldErrors := reflection.ValidateStructSlice(nil, rows, 0, reflection.ValidateOptions{
+//	    NameTag:  "json",
+//	    RulesTag: "validate",
+//	})
+//	// fieldErrors: [FieldError{FieldName: "[4512].email", ...}]
+func ValidateStructSlice[T any](validateFunc func(any) error, rows []T, workers int, opts ValidateOptions) []FieldError {
+	return ValidateStructSeq(validateFunc, slices.Values(rows), workers, opts)
+}
+
+// ValidateStructSeq validates the struct rows of an iterator concurrently
+// using ValidateStructFieldsWithOptions with a pool of workers goroutines.
+// If workers is zero or negative, then runtime.GOMAXPROCS(0) workers are used.
+//
+// The type T must be a struct or a pointer to a struct.
+// The names of the returned FieldErrors start with opts.NamePrefix
+// followed by the index of the row in brackets like "[4512].Email".
+// The errors are sorted by row index and within a row
+// in the same order as returned by ValidateStructFieldsWithOptions.
+// A nil pointer row is reported with an error for the row index.
+//
+// The rows are validated as pointers to copies of the iterated values,
+// so the validation function is also called with field addresses
+// like when passing a pointer to ValidateStructFields.
+//
+// The options are parsed once for all rows.
+// A panic of the validation of a row, for example because of an unknown rule,
+// stops the validation and is re-raised on the calling goroutine.
+func ValidateStructSeq[T any](validateFunc func(any) error, rows iter.Seq[T], workers int, opts ValidateOptions) []FieldError {
+	if t := reflect.TypeFor[T](); DerefType(t).Kind() != reflect.Struct {
+		panic(fmt.Errorf("%s is not a struct or pointer to a struct", t))
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	// Parse the options once instead of for every row
+	vd := newValidator(validateFunc, &opts)
+	namePrefix := opts.NamePrefix
+
+	type job struct {
+		index int
+		row   T
+	}
+	var (
+		jobs      = make(chan job, workers)
+		wg        sync.WaitGroup
+		mtx       sync.Mutex
+		rowErrors = make(map[int][]FieldError)
+		panicked  atomic.Bool
+		panicVal  any
+	)
+	validateRow := func(j job) {
+		defer func() {
+			if r := recover(); r != nil && !panicked.Swap(true) {
+				panicVal = r
+			}
+		}()
+		rowName := namePrefix + "[" + strconv.Itoa(j.index) + "]"
+		var fieldErrors []FieldError
+		if v, _ := DerefValueAndType(&j.row); v.Kind() != reflect.Struct {
+			fieldErrors = []FieldError{newFieldError(ValidationField{Name: rowName, GoName: rowName}, errors.New("is nil"))}
+		} else {
+			fieldErrors = vd.validateStruct(v, rowName+".")
+		}
+		if len(fieldErrors) > 0 {
+			mtx.Lock()
+			rowErrors[j.index] = fieldErrors
+			mtx.Unlock()
+		}
+	}
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				// Keep receiving after a panic so that sending doesn't block
+				if !panicked.Load() {
+					validateRow(j)
+				}
+			}
+		}()
+	}
+
+	func() {
+		// Stop the workers also if the rows iterator panics
+		defer wg.Wait()
+		defer close(jobs)
+		index := 0
+		for row := range rows {
+			if panicked.Load() {
+				break
+			}
+			jobs <- job{index: index, row: row}
+			index++
+		}
+	}()
+	if panicked.Load() {
+		// Re-panic on the calling goroutine where it can be recovered
+		panic(panicVal)
+	}
+
+	var fieldErrors []FieldError
+	for _, i := range slices.Sorted(maps.Keys(rowErrors)) {
+		fieldErrors = append(fieldErrors, rowErrors[i]...)
+	}
+	return fieldErrors
+}
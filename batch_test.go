@@ -0,0 +1,121 @@
+package reflection
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type batchRow struct {
+	ID    int    `json:"id"`
+	Email string `json:"email" validate:"required,email"`
+	Tags  []string
+}
+
+func newBatchRows(n int) []batchRow {
+	rows := make([]batchRow, n)
+	for i := range rows {
+		rows[i] = batchRow{ID: i, Email: fmt.Sprintf("user%d@example.com", i)}
+		if i%7 == 3 {
+			rows[i].Email = "invalid"
+		}
+		if i%11 == 5 {
+			rows[i].Tags = []string{"", "tag"}
+		}
+	}
+	return rows
+}
+
+func TestValidateStructSlice(t *testing.T) {
+	rows := newBatchRows(1000)
+	errEmpty := errors.New("empty")
+	validateFunc := func(v any) error {
+		if s, ok := v.(string); ok && s == "" {
+			return errEmpty
+		}
+		return nil
+	}
+	opts := ValidateOptions{NamePrefix: "rows", NameTag: "json", RulesTag: "validate"}
+
+	var expected []FieldError
+	for i := range rows {
+		rowOpts := opts
+		rowOpts.NamePrefix = fmt.Sprintf("rows[%d].", i)
+		expected = append(expected, ValidateStructFieldsWithOptions(validateFunc, &rows[i], rowOpts)...)
+	}
+	assert.Equal(t, "rows[3].email", expected[0].FieldName)
+	assert.Equal(t, "rows[5].Tags[0]", expected[1].FieldName)
+
+	for _, workers := range []int{0, 1, 8} {
+		assert.Equal(t, expected, ValidateStructSlice(validateFunc, rows, workers, opts), "workers: %d", workers)
+	}
+
+	ptrRows := make([]*batchRow, len(rows))
+	for i := range rows {
+		ptrRows[i] = &rows[i]
+	}
+	ptrRows[2] = nil
+	fieldErrors := ValidateStructSlice(validateFunc, ptrRows, 4, opts)
+	assert.Equal(t, "rows[2]: is nil", fieldErrors[0].Error())
+	assert.Equal(t, expected, fieldErrors[1:])
+
+	seqErrors := ValidateStructSeq(nil, maps.Values(map[int]batchRow{0: {Email: "x"}}), 2, ValidateOptions{RulesTag: "validate"})
+	if assert.Len(t, seqErrors, 1) {
+		assert.True(t, strings.HasPrefix(seqErrors[0].FieldName, "[0]."))
+	}
+	assert.Empty(t, ValidateStructSeq(nil, slices.Values([]batchRow(nil)), 2, opts))
+	assert.Panics(t, func() { ValidateStructSlice(nil, []int{1}, 1, opts) })
+
+	// Panics of workers are re-raised on the calling goroutine
+	type unknownRuleRow struct {
+		Name string `validate:"unknown_rule"`
+	}
+	for _, workers := range []int{1, 4} {
+		assert.Panics(t, func() {
+			ValidateStructSlice(nil, make([]unknownRuleRow, 100), workers, ValidateOptions{RulesTag: "validate"})
+		}, "workers: %d", workers)
+	}
+
+	// The workers are stopped if the iterator panics
+	goroutines := runtime.NumGoroutine()
+	assert.PanicsWithValue(t, "iterator", func() {
+		ValidateStructSeq(nil, func(yield func(batchRow) bool) {
+			for range 10 {
+				if !yield(batchRow{}) {
+					return
+				}
+			}
+			panic("iterator")
+		}, 4, opts)
+	})
+	for i := 0; i < 1000 && runtime.NumGoroutine() > goroutines; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutines)
+}
+
+func BenchmarkValidateStructSlice(b *testing.B) {
+	rows := newBatchRows(10000)
+	opts := ValidateOptions{NameTag: "json", RulesTag: "validate"}
+	b.Run("sequential", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			for i := range rows {
+				ValidateStructFieldsWithOptions(nil, &rows[i], opts)
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			ValidateStructSlice(nil, rows, 0, opts)
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package reflection
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// FieldError represents a validation error for a specific struct field.
+// It combines the field name with its validation error.
+//
+// The validation functions of this package also set the Go field name,
+// value, and the name of the failed rule, use the methods Path, GoPath, and Tag
+// for the parsed name paths and struct tag of the field.
+//
+// FieldError is comparable with == as long as Value holds a comparable value.
+type FieldError struct {
+	FieldName  string // Name of the field that failed validation
+	FieldError error  // The validation error for this field
+
+	GoName string // FieldName with the Go field names instead of the tag names
+	Value  any    // Value of the field that failed validation
+	Rule   string // Name of the failed validation rule or empty for other errors
+	Label  string // Display name of the field from the `label` struct tag, see LocalizedMessage
+
+	tag string // Tag of the field from the NameResolver used for the name
+}
+
+func newFieldError(f ValidationField, err error) FieldError {
+	fieldErr := FieldError{
+		FieldName:  f.Name,
+		FieldError: err,
+		GoName:     f.GoName,
+		Label:      f.Field.Tag.Get("label"),
+		tag:        f.Tag.String(),
+	}
+	if f.Value.IsValid() && f.Value.CanInterface() {
+		fieldErr.Value = f.Value.Interface()
+	}
+	var ruleErr *RuleError
+	if errors.As(err, &ruleErr) {
+		fieldErr.Rule = ruleErr.Rule
+	}
+	return fieldErr
+}
+
+// Path returns the parsed segments of FieldName
+// or nil if FieldName is not a valid path.
+func (f FieldError) Path() FieldPath {
+	p, _ := ParseFieldPath(f.FieldName)
+	return p
+}
+
+// GoPath returns the parsed segments of GoName
+// or nil if GoName is not a valid path.
+func (f FieldError) GoPath() FieldPath {
+	p, _ := ParseFieldPath(f.GoName)
+	return p
+}
+
+// Tag returns the parsed tag of the field
+// from the NameResolver used for the name.
+func (f FieldError) Tag() Tag {
+	return ParseTag(f.tag)
+}
+
+// withPrefix returns a copy of the error with the path of a field
+// prepended to the names, separated by a dot unless
+// the names start with an index or map key segment.
+func (f FieldError) withPrefix(name, goName string) FieldError {
+	join := func(prefix, name string) string {
+		switch {
+		case prefix == "":
+			return name
+		case name == "" || name[0] == '[' || name[0] == '{':
+			return prefix + name
+		}
+		return prefix + "." + name
+	}
+	if f.GoName == "" {
+		f.GoName = f.FieldName
+	}
+	f.FieldName = join(name, f.FieldName)
+	f.GoName = join(goName, f.GoName)
+	return f
+}
+
+// Error implements the error interface, formatting the error as "FieldName: error message".
+// Errors of the validated struct itself without a name prefix
+// have an empty FieldName and are formatted only as "error message".
+func (f FieldError) Error() string {
+	if f.FieldName == "" {
+		return f.FieldError.Error()
+	}
+	return f.FieldName + ": " + f.FieldError.Error()
+}
+
+// Unwrap returns the validation error for errors.Is and errors.As.
+func (f FieldError) Unwrap() error {
+	return f.FieldError
+}
+
+// fieldErrorJSON is the JSON representation of a FieldError.
+type fieldErrorJSON struct {
+	Field   string `json:"field"`
+	GoField string `json:"goField,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// MarshalJSON implements the json.Marshaler interface
+// as object with the field name, Go field path, rule, and error message.
+// The value is not included because it could contain sensitive data.
+func (f FieldError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fieldErrorJSON{
+		Field:   f.FieldName,
+		GoField: f.GoName,
+		Rule:    f.Rule,
+		Message: f.FieldError.Error(),
+	})
+}
+
+// FieldErrors is a slice of FieldError implementing the error interface.
+// The validation functions return []FieldError
+// that can be converted to FieldErrors.
+//
+// Example:
+//
+//	err := reflection.FieldErrors(reflection.ValidateStructRules(user, "", "json")).Err()
+//	if errors.Is(err, ErrNotFound) {
+//	    ...
+//	}
+type FieldErrors []FieldError
+
+// Error implements the error interface
+// and returns the errors separated by newlines.
+func (e FieldErrors) Error() string {
+	var b strings.Builder
+	for i, f := range e {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(f.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the FieldErrors as []error for errors.Is and errors.As.
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, f := range e {
+		errs[i] = f
+	}
+	return errs
+}
+
+// Err returns nil if there are no errors, else the FieldErrors as error.
+// Use it to avoid returning a non nil error interface for an empty slice.
+func (e FieldErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ByField groups the errors by their FieldName
+// keeping the order of the errors per field.
+func (e FieldErrors) ByField() map[string]FieldErrors {
+	if len(e) == 0 {
+		return nil
+	}
+	byField := make(map[string]FieldErrors)
+	for _, f := range e {
+		byField[f.FieldName] = append(byField[f.FieldName], f)
+	}
+	return byField
+}
+
+// Messages returns the error messages grouped by FieldName,
+// which is a common format for API error responses.
+//
+// Example:
+//
+//	json.NewEncoder(w).Encode(fieldErrors.Messages())
+//	// {"email":["must be a valid email address"],"name":["is required"]}
+func (e FieldErrors) Messages() map[string][]string {
+	if len(e) == 0 {
+		return nil
+	}
+	messages := make(map[string][]string)
+	for _, f := range e {
+		messages[f.FieldName] = append(messages[f.FieldName], f.FieldError.Error())
+	}
+	return messages
+}
+
+// Fields returns the names of the fields with errors
+// in the order of their first error without duplicates.
+func (e FieldErrors) Fields() []string {
+	var names []string
+	seen := make(map[string]bool, len(e))
+	for _, f := range e {
+		if !seen[f.FieldName] {
+			seen[f.FieldName] = true
+			names = append(names, f.FieldName)
+		}
+	}
+	return names
+}
@@ -0,0 +1,83 @@
+package reflection
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldError(t *testing.T) {
+	type Address struct {
+		Street string `json:"street" validate:"required"`
+	}
+	type User struct {
+		Name      string    `json:"name,omitempty" validate:"required,min=3"`
+		Addresses []Address `json:"addresses"`
+		Secret    string    `json:"secret" validate:"len=4"`
+	}
+
+	errSecret := errors.New("wrong secret")
+	fieldErrors := FieldErrors(ValidateStructFieldsWithOptions(
+		func(v any) error {
+			if s, ok := v.(string); ok && s == "x" {
+				return errSecret
+			}
+			return nil
+		},
+		User{Name: "Al", Addresses: []Address{{}, {}}, Secret: "x"},
+		ValidateOptions{NameTag: "json", RulesTag: "validate"},
+	))
+	require.Len(t, fieldErrors, 5)
+
+	name := fieldErrors[0]
+	assert.Equal(t, "name", name.FieldName)
+	assert.Equal(t, FieldPath{FieldSegment("name")}, name.Path())
+	assert.Equal(t, FieldPath{FieldSegment("Name")}, name.GoPath())
+	assert.True(t, name.Tag().HasOption("omitempty"))
+	assert.Equal(t, "Al", name.Value)
+	assert.Equal(t, "min", name.Rule)
+
+	street := fieldErrors[2]
+	assert.Equal(t, "addresses[1].street", street.FieldName)
+	assert.Equal(t, FieldPath{FieldSegment("addresses"), IndexSegment(1), FieldSegment("street")}, street.Path())
+	assert.Equal(t, "Addresses[1].Street", street.GoName)
+	assert.Equal(t, "required", street.Rule)
+
+	assert.Equal(t, "secret", fieldErrors[4].FieldName)
+	assert.Equal(t, "", fieldErrors[4].Rule)
+
+	var ruleErr *RuleError
+	assert.ErrorAs(t, name, &ruleErr)
+	assert.Equal(t, "3", ruleErr.Param)
+	assert.ErrorIs(t, fieldErrors[4], errSecret)
+	assert.ErrorIs(t, fieldErrors.Err(), fieldErrors[4])
+	assert.True(t, fieldErrors[4] == fieldErrors[4].withPrefix("", ""))
+	assert.Nil(t, FieldError{FieldName: "[invalid"}.Path())
+	assert.ErrorIs(t, fieldErrors.Err(), errSecret)
+	assert.ErrorAs(t, fieldErrors.Err(), &ruleErr)
+	assert.NoError(t, FieldErrors(nil).Err())
+
+	assert.Equal(t, "name: must have at least 3 characters\n"+
+		"addresses[0].street: is required\n"+
+		"addresses[1].street: is required\n"+
+		"secret: must have exactly 4 characters\n"+
+		"secret: wrong secret", fieldErrors.Error())
+
+	assert.Equal(t, []string{"name", "addresses[0].street", "addresses[1].street", "secret"}, fieldErrors.Fields())
+	byField := fieldErrors.ByField()
+	assert.Len(t, byField, 4)
+	assert.Len(t, byField["secret"], 2)
+	assert.Equal(t, map[string][]string{
+		"name":                {"must have at least 3 characters"},
+		"addresses[0].street": {"is required"},
+		"addresses[1].street": {"is required"},
+		"secret":              {"must have exactly 4 characters", "wrong secret"},
+	}, fieldErrors.Messages())
+
+	data, err := json.Marshal(fieldErrors[:1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"field":"name","goField":"Name","rule":"min","message":"must have at least 3 characters"}]`, string(data))
+}
@@ -0,0 +1,210 @@
+package reflection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// DefaultMessageLocale is the locale of the built-in message templates
+// and the fallback for locales without a template for a rule.
+const DefaultMessageLocale = "en"
+
+// MessageData is the data passed to the message templates
+// registered with RegisterValidationMessage.
+type MessageData struct {
+	Label  string   // Display name of the field from the `label` struct tag or the field name
+	Field  string   // Name of the field including the name prefix (e.g., "address.street")
+	Rule   string   // Name of the failed validation rule
+	Param  string   // Parameter of the rule (e.g., "3" for "min=3")
+	Params []string // Parameter split at spaces (e.g., ["free", "pro"] for "oneof=free pro")
+	Args   []any    // Additional arguments of the rule, see RuleError.Args
+	Value  any      // Value of the field that failed validation
+	Kind   string   // "string", "collection", "number", or empty for other values
+	Err    error    // Error of the rule with the English message
+}
+
+var (
+	validationMessagesMtx sync.RWMutex
+	validationMessages    = map[string]map[string]*template.Template{} // locale -> rule -> template
+
+	messageFuncs = template.FuncMap{"join": strings.Join}
+)
+
+func init() {
+	for rule, text := range map[string]string{
+		"required": `{{.Label}} is required`,
+		"min":      `{{.Label}} must have at least {{.Param}}{{if eq .Kind "string"}} characters{{else if eq .Kind "collection"}} elements{{end}}`,
+		"max":      `{{.Label}} must have at most {{.Param}}{{if eq .Kind "string"}} characters{{else if eq .Kind "collection"}} elements{{end}}`,
+		"len":      `{{.Label}} must have exactly {{.Param}}{{if eq .Kind "string"}} characters{{else if eq .Kind "collection"}} elements{{end}}`,
+		"oneof":    `{{.Label}} must be one of {{join .Params ", "}}`,
+		"email":    `{{.Label}} must be a valid email address`,
+		"url":      `{{.Label}} must be a valid URL`,
+		"uuid":     `{{.Label}} must be a valid UUID`,
+		"regexp":   `{{.Label}} must match the pattern {{.Param}}`,
+		"eqfield":  `{{.Label}} must be equal to {{.Param}}`,
+		"nefield":  `{{.Label}} must not be equal to {{.Param}}`,
+		"gtfield":  `{{.Label}} must be greater than {{.Param}}`,
+		"gtefield": `{{.Label}} must be greater than or equal to {{.Param}}`,
+		"ltfield":  `{{.Label}} must be less than {{.Param}}`,
+		"ltefield": `{{.Label}} must be less than or equal to {{.Param}}`,
+
+		"required_if":      `{{.Label}} is required if {{index .Params 0}} is {{join (slice .Params 1) " or "}}`,
+		"required_unless":  `{{.Label}} is required unless {{index .Params 0}} is {{join (slice .Params 1) " or "}}`,
+		"required_with":    `{{.Label}} is required if {{index .Args 0}} is set`,
+		"required_without": `{{.Label}} is required if {{index .Args 0}} is not set`,
+	} {
+		RegisterValidationMessage(DefaultMessageLocale, rule, text)
+	}
+}
+
+// RegisterValidationMessage registers a text/template for the error message
+// of a validation rule in a locale like "de" or "de-AT".
+// The template is executed with MessageData and the function
+// join (strings.Join) is available in addition to the built-in functions.
+// Registering a template for an existing locale and rule replaces it.
+//
+// English templates for all built-in rules are registered
+// for the DefaultMessageLocale "en".
+// Custom rules without a template fall back to their error message.
+//
+// Panics if the template can't be parsed.
+//
+// Example:
+//
+//	reflection.RegisterValidationMessage("de", "required", "{{.Label}} ist erforderlich")
+//	reflection.RegisterValidationMessage("de", "min", `{{.Label}} muss mindestens {{.Param}}{{if eq .Kind "string"}} Zeichen{{end}} haben`)
+func RegisterValidationMessage(locale, rule, text string) {
+	tmpl, err := template.New(rule).Funcs(messageFuncs).Parse(text)
+	if err != nil {
+		panic(fmt.Errorf("invalid message template for validation rule %q in locale %q: %w", rule, locale, err))
+	}
+	locale = normalizeLocale(locale)
+
+	validationMessagesMtx.Lock()
+	defer validationMessagesMtx.Unlock()
+
+	if validationMessages[locale] == nil {
+		validationMessages[locale] = make(map[string]*template.Template)
+	}
+	validationMessages[locale][rule] = tmpl
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
+
+// validationMessage returns the template for rule in locale,
+// falling back to the language of the locale without region
+// and then to DefaultMessageLocale.
+func validationMessage(locale, rule string) *template.Template {
+	locale = normalizeLocale(locale)
+
+	validationMessagesMtx.RLock()
+	defer validationMessagesMtx.RUnlock()
+
+	for {
+		if tmpl := validationMessages[locale][rule]; tmpl != nil {
+			return tmpl
+		}
+		if i := strings.LastIndexByte(locale, '-'); i > 0 {
+			locale = locale[:i]
+			continue
+		}
+		if locale == DefaultMessageLocale {
+			return nil
+		}
+		locale = DefaultMessageLocale
+	}
+}
+
+// label returns the Label of the error or the name
+// of the last field in its path if the Label is empty.
+func (f FieldError) label() string {
+	if f.Label != "" {
+		return f.Label
+	}
+	path := f.Path()
+	for i := len(path) - 1; i >= 0; i-- {
+		if !path[i].IsIndex && !path[i].IsKey {
+			return path[i].Field
+		}
+	}
+	return f.FieldName
+}
+
+// LocalizedMessage returns the error message of a failed validation rule
+// rendered with the template registered for the rule and locale,
+// see RegisterValidationMessage.
+// Errors without a rule or template are formatted as "Label: error message"
+// or only as "error message" if there is no label.
+//
+// Example:
+//
+//	type User struct {
+//	    Name string `json:"name" label:"Full name" validate:"required"`
+//	}
+//
+//	fieldErrors := reflection.ValidateStructRules(User{}, "", "json")
+//	fieldErrors[0].LocalizedMessage("en") // Full name is required
+//	fieldErrors[0].LocalizedMessage("de") // Full name ist erforderlich
+func (f FieldError) LocalizedMessage(locale string) string {
+	label := f.label()
+	var ruleErr *RuleError
+	if f.Rule != "" && errors.As(f.FieldError, &ruleErr) {
+		if tmpl := validationMessage(locale, f.Rule); tmpl != nil {
+			data := MessageData{
+				Label:  label,
+				Field:  f.FieldName,
+				Rule:   ruleErr.Rule,
+				Param:  ruleErr.Param,
+				Params: strings.Fields(ruleErr.Param),
+				Args:   ruleErr.Args,
+				Value:  f.Value,
+				Kind:   messageKind(f.Value),
+				Err:    ruleErr.Err,
+			}
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, data); err == nil {
+				return b.String()
+			}
+		}
+	}
+	if label == "" {
+		return f.FieldError.Error()
+	}
+	return label + ": " + f.FieldError.Error()
+}
+
+// messageKind returns the kind of a value for size dependent messages.
+func messageKind(value any) string {
+	v, _ := ruleValue(ValidationField{Value: reflect.ValueOf(value)})
+	switch v.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "collection"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return ""
+}
+
+// LocalizedMessages returns the localized error messages grouped by FieldName
+// like Messages, see FieldError.LocalizedMessage.
+func (e FieldErrors) LocalizedMessages(locale string) map[string][]string {
+	if len(e) == 0 {
+		return nil
+	}
+	messages := make(map[string][]string)
+	for _, f := range e {
+		messages[f.FieldName] = append(messages[f.FieldName], f.LocalizedMessage(locale))
+	}
+	return messages
+}
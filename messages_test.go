@@ -0,0 +1,80 @@
+package reflection
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalizedMessage(t *testing.T) {
+	RegisterValidationMessage("test-de", "required", "{{.Label}} ist erforderlich")
+	RegisterValidationMessage("test-de", "min", `{{.Label}} muss mindestens {{.Param}}{{if eq .Kind "string"}} Zeichen{{end}} haben`)
+	RegisterValidationMessage("test-de", "required_with", "{{.Label}} ist erforderlich, wenn {{index .Args 0}} gesetzt ist")
+	assert.Panics(t, func() { RegisterValidationMessage("test-de", "max", "{{.Label") })
+
+	type Contact struct {
+		Name      string   `json:"name" label:"Full name" validate:"required"`
+		Nickname  string   `json:"nickname" validate:"min=3"`
+		Tags      []string `json:"tags" label:"Tags" validate:"min=1"`
+		Plan      string   `json:"plan" validate:"oneof=free pro"`
+		Phone     string   `json:"phone"`
+		PhoneType string   `json:"phoneType" label:"Phone type" validate:"required_with=phone"`
+		Country   string   `json:"country" validate:"required_if=phone 1 2"`
+		Remark    string   `json:"remark"`
+	}
+	errRemark := errors.New("invalid remark")
+	fieldErrors := FieldErrors(ValidateStructFieldsWithOptions(
+		func(v any) error {
+			if s, ok := v.(string); ok && s == "!" {
+				return errRemark
+			}
+			return nil
+		},
+		Contact{Nickname: "Al", Plan: "team", Phone: "1", Remark: "!"},
+		ValidateOptions{NameTag: "json", RulesTag: "validate"},
+	))
+
+	assert.Equal(t, map[string][]string{
+		"name":      {"Full name is required"},
+		"nickname":  {"nickname must have at least 3 characters"},
+		"tags":      {"Tags must have at least 1 elements"},
+		"plan":      {"plan must be one of free, pro"},
+		"phoneType": {"Phone type is required if phone is set"},
+		"country":   {"country is required if phone is 1 or 2"},
+		"remark":    {"remark: invalid remark"},
+	}, fieldErrors.LocalizedMessages("en"))
+
+	assert.Equal(t, map[string][]string{
+		"name":      {"Full name ist erforderlich"},
+		"nickname":  {"nickname muss mindestens 3 Zeichen haben"},
+		"tags":      {"Tags muss mindestens 1 haben"},
+		"plan":      {"plan must be one of free, pro"},
+		"phoneType": {"Phone type ist erforderlich, wenn phone gesetzt ist"},
+		"country":   {"country is required if phone is 1 or 2"},
+		"remark":    {"remark: invalid remark"},
+	}, fieldErrors.LocalizedMessages("test_DE-at"))
+
+	assert.Equal(t, "Full name", fieldErrors[0].Label)
+	assert.Equal(t, "is required", fieldErrors[0].FieldError.Error())
+	assert.Equal(t, "invalid", FieldError{FieldError: errors.New("invalid")}.LocalizedMessage("en"))
+	assert.Nil(t, FieldErrors(nil).LocalizedMessages("en"))
+
+	// The label of map keys and values is the name of the map field
+	errKey := errors.New("invalid key")
+	fieldErrors = FieldErrors(ValidateStructFields(
+		func(v any) error {
+			if s, ok := v.(string); ok && s == "" {
+				return errKey
+			}
+			return nil
+		},
+		struct {
+			Labels map[string]string `json:"labels"`
+		}{Labels: map[string]string{"": "x"}},
+		"", "json",
+	))
+	assert.Equal(t, map[string][]string{
+		`labels{""}`: {"labels: invalid key"},
+	}, fieldErrors.LocalizedMessages("en"))
+}
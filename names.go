@@ -0,0 +1,139 @@
+package reflection
+
+import (
+	"reflect"
+	"sync"
+)
+
+// NameResolver resolves the names of struct fields
+// from a priority list of struct tag keys
+// with the Go field name as fallback.
+//
+// The first tag with a non empty name is used.
+// A tag with the name "-" ignores the field
+// so that lower priority tags are not checked.
+//
+// The resolved names are cached per struct type by the NameResolver,
+// so a NameResolver must not be modified or copied after its first use.
+// Create resolvers once and reuse them, like package level variables,
+// to avoid resolving the names again for every new resolver.
+//
+// Example:
+//
+//	type User struct {
+//	    ID        int    `db:"user_id" json:"id"`
+//	    Name      string `json:"name"`
+//	    CreatedAt string
+//	}
+//	resolver := reflection.NewNameResolver("db", "json")
+//	names := reflection.FlatStructFieldTagsOrNames(reflect.TypeOf(User{}), resolver)
+//	fmt.Println(names) // [user_id name CreatedAt]
+type NameResolver struct {
+	// Tags is the priority list of struct tag keys for field names
+	Tags []string
+
+	// FieldName optionally converts the Go field name
+	// that is used when none of the Tags defines a name,
+	// like the naming strategies SnakeCase or CamelCase.
+	// If nil, then the Go field name is used unchanged.
+	FieldName NamingStrategy
+
+	// tagInfos caches *structTagInfo by *structTypeInfo
+	tagInfos sync.Map
+}
+
+// NewNameResolver returns a NameResolver for the priority list
+// of struct tag keys with the unchanged Go field name as fallback.
+func NewNameResolver(tags ...string) *NameResolver {
+	return &NameResolver{Tags: tags}
+}
+
+// NewNamingResolver returns a NameResolver for the priority list
+// of struct tag keys that uses the naming strategy
+// to convert the Go field name as fallback.
+//
+// Example:
+//
+//	type Config struct {
+//	    HTTPServer string
+//	    UserID     int `env:"USER"`
+//	}
+//	resolver := reflection.NewNamingResolver(reflection.ScreamingSnakeCase, "env")
+//	names := reflection.FlatStructFieldTagsOrNames(reflect.TypeOf(Config{}), resolver)
+//	fmt.Println(names) // [HTTP_SERVER USER]
+func NewNamingResolver(naming NamingStrategy, tags ...string) *NameResolver {
+	return &NameResolver{Tags: tags, FieldName: naming}
+}
+
+// NameSource is the type constraint for the name parameters
+// of the functions in this package.
+// A string is used as struct tag key for the names of fields
+// with the Go field name as fallback,
+// an empty string uses only the Go field names.
+// A *NameResolver can be used for more complex name resolution.
+type NameSource interface {
+	string | *NameResolver
+}
+
+var tagNameResolvers sync.Map // string -> *NameResolver
+
+// nameResolverOf returns a *NameResolver for a NameSource
+// using a cached NameResolver for every tag key string.
+func nameResolverOf[N NameSource](nameSource N) *NameResolver {
+	// Switch on a pointer because converting a string to any allocates
+	switch n := any(&nameSource).(type) {
+	case **NameResolver:
+		if *n != nil {
+			return *n
+		}
+		return tagNameResolver("")
+	case *string:
+		return tagNameResolver(*n)
+	}
+	panic("unreachable")
+}
+
+func tagNameResolver(tagKey string) *NameResolver {
+	if r, ok := tagNameResolvers.Load(tagKey); ok {
+		return r.(*NameResolver)
+	}
+	r := new(NameResolver)
+	if tagKey != "" {
+		r.Tags = []string{tagKey}
+	}
+	actual, _ := tagNameResolvers.LoadOrStore(tagKey, r)
+	return actual.(*NameResolver)
+}
+
+// lookupTag returns the parsed tag of the first tag key of r.Tags
+// that has a non empty name.
+// If no tag has a name, then the options of the first found tag
+// are returned with an empty name.
+func (r *NameResolver) lookupTag(field reflect.StructField) fieldTag {
+	var first fieldTag
+	for _, key := range r.Tags {
+		tag, found := LookupTag(field, key)
+		if !found {
+			continue
+		}
+		if tag.Name != "" {
+			return fieldTag{Tag: tag, Found: true}
+		}
+		if !first.Found {
+			first = fieldTag{Tag: tag, Found: true}
+		}
+	}
+	return first
+}
+
+// fieldName returns the name of the tag
+// or the possibly converted Go field name.
+func (r *NameResolver) fieldName(field reflect.StructField, tag fieldTag) string {
+	if tag.Name != "" {
+		return tag.Name
+	}
+	if r.FieldName != nil {
+		return r.FieldName(field.Name)
+	}
+	return field.Name
+}
@@ -0,0 +1,85 @@
+package reflection
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameResolver(t *testing.T) {
+	type Base struct {
+		ID int `db:"id" json:"identifier"`
+	}
+	type User struct {
+		Base
+		Name      string `json:"name"`
+		Email     string `db:"email_address" json:"email" form:"mail"`
+		Password  string `db:"-" json:"password"`
+		CreatedAt string `db:",omitempty" json:"created"`
+		UpdatedAt string
+	}
+	typ := reflect.TypeOf(User{})
+
+	resolver := NewNameResolver("db", "json")
+	assert.Equal(t,
+		[]string{"id", "name", "email_address", "-", "created", "UpdatedAt"},
+		FlatStructFieldTagsOrNames(typ, resolver),
+	)
+
+	fields := FlatExportedNamedStructFields(typ, resolver)
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	assert.Equal(t, []string{"id", "name", "email_address", "created", "UpdatedAt"}, names)
+
+	lower := &NameResolver{Tags: []string{"form"}, FieldName: strings.ToLower}
+	assert.Equal(t,
+		[]string{"id", "name", "mail", "password", "createdat", "updatedat"},
+		FlatStructFieldTagsOrNames(typ, lower),
+	)
+
+	zeroNames := ZeroValueExportedStructFieldNames(User{Name: "Name"}, "", resolver)
+	assert.ElementsMatch(t, []string{"id", "email_address", "created", "UpdatedAt"}, zeroNames)
+
+	valueNames := FlatExportedStructFieldValueNameMap(&User{Email: "a@b.c"}, resolver)
+	assert.Equal(t, "a@b.c", valueNames["email_address"].Value.Interface())
+
+	// A nil *NameResolver uses the Go field names
+	assert.Equal(t, FlatStructFieldNames(typ), FlatStructFieldTagsOrNames(typ, (*NameResolver)(nil)))
+}
+
+func TestNameResolverCache(t *testing.T) {
+	type Account struct {
+		ID     int    `db:"account_id" json:"id"`
+		UserID string `json:"-"`
+		Name   string `json:"name"`
+	}
+	typ := reflect.TypeOf(Account{})
+
+	// Every resolver has its own cache that is garbage collected with it
+	resolver := NewNameResolver("db")
+	assert.Equal(t, []string{"account_id", "UserID", "Name"}, FlatStructFieldTagsOrNames(typ, resolver))
+	countTagInfos := func(r *NameResolver) (n int) {
+		r.tagInfos.Range(func(any, any) bool { n++; return true })
+		return n
+	}
+	assert.Equal(t, 1, countTagInfos(resolver))
+	FlatStructFieldTagsOrNames(typ, resolver)
+	assert.Equal(t, 1, countTagInfos(resolver))
+
+	// Closures of the same function literal don't share cached names
+	prefixed := func(prefix string) NamingStrategy {
+		return func(name string) string { return prefix + name }
+	}
+	assert.Equal(t,
+		[]string{"account_id", "a_UserID", "a_Name"},
+		FlatStructFieldTagsOrNames(typ, NewNamingResolver(prefixed("a_"), "db")),
+	)
+	assert.Equal(t,
+		[]string{"account_id", "b_UserID", "b_Name"},
+		FlatStructFieldTagsOrNames(typ, NewNamingResolver(prefixed("b_"), "db")),
+	)
+}
@@ -0,0 +1,137 @@
+package reflection
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NamingStrategy converts a Go identifier
+// like a struct field name into another naming convention.
+type NamingStrategy func(goName string) string
+
+var (
+	// SnakeCase converts Go names to snake_case,
+	// for example UserID to user_id and HTTPServer to http_server.
+	SnakeCase NamingStrategy = func(goName string) string {
+		return joinWords(splitWords(goName), '_', strings.ToLower)
+	}
+
+	// ScreamingSnakeCase converts Go names to SCREAMING_SNAKE_CASE
+	// as used for environment variables,
+	// for example UserID to USER_ID and HTTPServer to HTTP_SERVER.
+	ScreamingSnakeCase NamingStrategy = func(goName string) string {
+		return joinWords(splitWords(goName), '_', strings.ToUpper)
+	}
+
+	// KebabCase converts Go names to kebab-case,
+	// for example UserID to user-id and HTTPServer to http-server.
+	KebabCase NamingStrategy = func(goName string) string {
+		return joinWords(splitWords(goName), '-', strings.ToLower)
+	}
+
+	// CamelCase converts Go names to camelCase
+	// with only the first letter of initialisms in upper case,
+	// for example UserID to userId and HTTPServer to httpServer.
+	CamelCase NamingStrategy = func(goName string) string {
+		words := splitWords(goName)
+		for i, word := range words {
+			if i == 0 {
+				words[i] = strings.ToLower(word)
+			} else {
+				words[i] = titleWord(word)
+			}
+		}
+		return strings.Join(words, "")
+	}
+)
+
+// mixedCaseWords are words with upper case letters after the first letter
+// that are not split into multiple words.
+var mixedCaseWords = []string{"OAuth", "GraphQL", "IPv4", "IPv6"}
+
+// splitWords splits a Go identifier into words at
+// underscores, lower to upper case transitions,
+// digit to upper case transitions and before the last upper case letter
+// of an initialism followed by a lower case letter.
+// A lower case s after an initialism that is not followed
+// by another lower case letter is kept as plural of the initialism.
+// Digits stay with the preceding word.
+// The mixedCaseWords like OAuth are not split.
+//
+// Examples:
+//
+//	UserID      -> User ID
+//	HTTPServer  -> HTTP Server
+//	UserIDs     -> User IDs
+//	Base64Data  -> Base64 Data
+//	OAuth2Token -> OAuth2 Token
+//	snake_case  -> snake case
+func splitWords(name string) (words []string) {
+	for _, part := range strings.FieldsFunc(name, isWordSeparator) {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			if i == start+1 {
+				// Continue after a mixed case word at the start of a word
+				i = start + max(mixedCaseWordLen(runes[start:]), 1)
+				if i >= len(runes) {
+					break
+				}
+			}
+			prev, cur := runes[i-1], runes[i]
+			switch {
+			case unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			case unicode.IsUpper(cur) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) &&
+				!isPluralSuffix(runes[i+1:]):
+			default:
+				continue
+			}
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// mixedCaseWordLen returns the length of the mixed case word
+// at the start of runes or zero if there is none.
+// The word must not be followed by a lower case letter.
+func mixedCaseWordLen(runes []rune) int {
+	for _, word := range mixedCaseWords {
+		n := utf8.RuneCountInString(word)
+		if len(runes) >= n && string(runes[:n]) == word && (len(runes) == n || !unicode.IsLower(runes[n])) {
+			return n
+		}
+	}
+	return 0
+}
+
+// isPluralSuffix returns if runes start with a lower case s
+// that is not followed by another lower case letter.
+func isPluralSuffix(runes []rune) bool {
+	return runes[0] == 's' && (len(runes) == 1 || !unicode.IsLower(runes[1]))
+}
+
+func isWordSeparator(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsSpace(r)
+}
+
+func joinWords(words []string, sep byte, convert func(string) string) string {
+	var b strings.Builder
+	for i, word := range words {
+		if i > 0 {
+			b.WriteByte(sep)
+		}
+		b.WriteString(convert(word))
+	}
+	return b.String()
+}
+
+// titleWord returns word with the first letter in upper case
+// and all other letters in lower case.
+func titleWord(word string) string {
+	first, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(first)) + strings.ToLower(word[size:])
+}
@@ -0,0 +1,61 @@
+package reflection
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamingStrategies(t *testing.T) {
+	tests := []struct {
+		goName    string
+		snake     string
+		screaming string
+		kebab     string
+		camel     string
+	}{
+		{goName: "Name", snake: "name", screaming: "NAME", kebab: "name", camel: "name"},
+		{goName: "UserID", snake: "user_id", screaming: "USER_ID", kebab: "user-id", camel: "userId"},
+		{goName: "HTTPServer", snake: "http_server", screaming: "HTTP_SERVER", kebab: "http-server", camel: "httpServer"},
+		{goName: "ID", snake: "id", screaming: "ID", kebab: "id", camel: "id"},
+		{goName: "APIKeyID", snake: "api_key_id", screaming: "API_KEY_ID", kebab: "api-key-id", camel: "apiKeyId"},
+		{goName: "Base64Data", snake: "base64_data", screaming: "BASE64_DATA", kebab: "base64-data", camel: "base64Data"},
+		{goName: "OAuth2Token", snake: "oauth2_token", screaming: "OAUTH2_TOKEN", kebab: "oauth2-token", camel: "oauth2Token"},
+		{goName: "OAuthToken", snake: "oauth_token", screaming: "OAUTH_TOKEN", kebab: "oauth-token", camel: "oauthToken"},
+		{goName: "ServerIPv4", snake: "server_ipv4", screaming: "SERVER_IPV4", kebab: "server-ipv4", camel: "serverIpv4"},
+		{goName: "UserIDs", snake: "user_ids", screaming: "USER_IDS", kebab: "user-ids", camel: "userIds"},
+		{goName: "IDs", snake: "ids", screaming: "IDS", kebab: "ids", camel: "ids"},
+		{goName: "URLs", snake: "urls", screaming: "URLS", kebab: "urls", camel: "urls"},
+		{goName: "APIsList", snake: "apis_list", screaming: "APIS_LIST", kebab: "apis-list", camel: "apisList"},
+		{goName: "HTTPServers", snake: "http_servers", screaming: "HTTP_SERVERS", kebab: "http-servers", camel: "httpServers"},
+		{goName: "HTTPSet", snake: "http_set", screaming: "HTTP_SET", kebab: "http-set", camel: "httpSet"},
+		{goName: "already_snake", snake: "already_snake", screaming: "ALREADY_SNAKE", kebab: "already-snake", camel: "alreadySnake"},
+		{goName: "lowerCamel", snake: "lower_camel", screaming: "LOWER_CAMEL", kebab: "lower-camel", camel: "lowerCamel"},
+		{goName: "ÜberName", snake: "über_name", screaming: "ÜBER_NAME", kebab: "über-name", camel: "überName"},
+		{goName: "", snake: "", screaming: "", kebab: "", camel: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goName, func(t *testing.T) {
+			assert.Equal(t, tt.snake, SnakeCase(tt.goName), "SnakeCase")
+			assert.Equal(t, tt.screaming, ScreamingSnakeCase(tt.goName), "ScreamingSnakeCase")
+			assert.Equal(t, tt.kebab, KebabCase(tt.goName), "KebabCase")
+			assert.Equal(t, tt.camel, CamelCase(tt.goName), "CamelCase")
+		})
+	}
+}
+
+func TestNamingResolver(t *testing.T) {
+	type Config struct {
+		HTTPServer string
+		UserID     int `env:"USER"`
+		Ignored    int `env:"-"`
+	}
+	typ := reflect.TypeOf(Config{})
+
+	resolver := NewNamingResolver(ScreamingSnakeCase, "env")
+	assert.Equal(t, []string{"HTTP_SERVER", "USER", "-"}, FlatStructFieldTagsOrNames(typ, resolver))
+
+	zeroNames := ZeroValueExportedStructFieldNames(Config{}, "", NewNamingResolver(SnakeCase))
+	assert.Equal(t, []string{"http_server", "user_id", "ignored"}, zeroNames)
+}
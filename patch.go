@@ -0,0 +1,125 @@
+package reflection
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PresentJSONPaths returns the paths of all object members
+// and array elements of a JSON object in sorted order,
+// like "name", "address", "address.street", "tags", and "tags[0]",
+// for the use as ValidateOptions.PresentFields.
+// The JSON null value counts as present.
+// The result is not nil for an empty object.
+//
+// Example:
+//
+//	paths, err := reflection.PresentJSONPaths([]byte(`{"name":"","address":{"city":"Berlin"}}`))
+//	// paths: ["address", "address.city", "name"]
+func PresentJSONPaths(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var object map[string]any
+	if err := dec.Decode(&object); err != nil {
+		return nil, err
+	}
+	if object == nil {
+		return nil, errors.New("JSON is not an object")
+	}
+	paths := appendJSONPaths([]string{}, "", object)
+	slices.Sort(paths)
+	return paths, nil
+}
+
+func appendJSONPaths(paths []string, prefix string, value any) []string {
+	switch value := value.(type) {
+	case map[string]any:
+		if prefix != "" {
+			prefix += "."
+		}
+		for key, member := range value {
+			paths = append(paths, prefix+key)
+			paths = appendJSONPaths(paths, prefix+key, member)
+		}
+	case []any:
+		for i, elem := range value {
+			elemPath := prefix + IndexSegment(i).String()
+			paths = append(paths, elemPath)
+			paths = appendJSONPaths(paths, elemPath, elem)
+		}
+	}
+	return paths
+}
+
+// presentFields maps the normalized and case folded paths
+// of ValidateOptions.PresentFields to true
+// and the normalized paths of their parents to false.
+type presentFields map[string]bool
+
+func newPresentFields(paths []string) presentFields {
+	if paths == nil {
+		return nil
+	}
+	present := make(presentFields, len(paths))
+	for _, path := range paths {
+		p, err := ParseFieldPath(path)
+		if err != nil {
+			present[strings.ToLower(path)] = true
+			continue
+		}
+		for i := 1; i < len(p); i++ {
+			parent := presenceKey(p[:i])
+			if !present[parent] {
+				present[parent] = false
+			}
+		}
+		present[presenceKey(p)] = true
+	}
+	return present
+}
+
+// lookup returns if the field with name or one of its parents is present
+// and if it has to be traversed because it or its nested fields are present.
+// A nil presentFields treats all fields as present.
+func (present presentFields) lookup(name string) (isPresent, traverse bool) {
+	if present == nil {
+		return true, true
+	}
+	p, err := ParseFieldPath(name)
+	if err != nil {
+		name = strings.ToLower(name)
+		return present[name], present[name]
+	}
+	for i := 1; i <= len(p); i++ {
+		if present[presenceKey(p[:i])] {
+			return true, true
+		}
+	}
+	_, traverse = present[presenceKey(p)]
+	return false, traverse
+}
+
+// presenceKey formats a path with quoted string map keys
+// written like field names, so that the paths of JSON objects
+// match the paths of struct fields and map entries.
+// The path is case folded because encoding/json
+// matches JSON keys to struct fields case-insensitively.
+func presenceKey(p FieldPath) string {
+	var b strings.Builder
+	for i, s := range p {
+		if s.IsIndex {
+			if key, err := strconv.Unquote(s.Index); err == nil {
+				s = FieldSegment(key)
+			}
+		}
+		if i > 0 && !s.IsIndex && !s.IsKey {
+			b.WriteByte('.')
+		}
+		b.WriteString(s.String())
+	}
+	return strings.ToLower(b.String())
+}
@@ -0,0 +1,91 @@
+package reflection
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresentJSONPaths(t *testing.T) {
+	paths, err := PresentJSONPaths([]byte(`{"name":"","email":null,"address":{"city":"Berlin"},"tags":["a",{"b":1}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"address", "address.city", "email", "name", "tags", "tags[0]", "tags[1]", "tags[1].b"}, paths)
+
+	_, err = PresentJSONPaths([]byte(`[1]`))
+	assert.Error(t, err)
+	_, err = PresentJSONPaths([]byte(`null`))
+	assert.Error(t, err)
+}
+
+func TestValidatePresentFields(t *testing.T) {
+	type Address struct {
+		Street string `json:"street" validate:"required"`
+		City   string `json:"city" validate:"required"`
+	}
+	type UserPatch struct {
+		Name     *string            `json:"name" validate:"required,min=3"`
+		Email    *string            `json:"email" validate:"email"`
+		Age      int                `json:"age" validate:"min=18"`
+		Address  *Address           `json:"address"`
+		Contacts map[string]Address `json:"contacts"`
+	}
+	fieldNames := func(body string, opts ValidateOptions) []string {
+		var patch UserPatch
+		require.NoError(t, json.Unmarshal([]byte(body), &patch))
+		if opts.PresentFields != nil {
+			paths, err := PresentJSONPaths([]byte(body))
+			require.NoError(t, err)
+			opts.PresentFields = paths
+		}
+		opts.NameTag = "json"
+		opts.RulesTag = "validate"
+		return FieldErrors(ValidateStructFieldsWithOptions(nil, &patch, opts)).Fields()
+	}
+
+	// Without presence all fields are validated
+	assert.Equal(t,
+		[]string{"name", "age", "address.street"},
+		fieldNames(`{"address":{"city":"Berlin"}}`, ValidateOptions{}),
+	)
+	// Nil fields are skipped, the zero age can't be distinguished from an absent age
+	assert.Equal(t,
+		[]string{"age", "address.street"},
+		fieldNames(`{"address":{"city":"Berlin"}}`, ValidateOptions{PresentOnly: true}),
+	)
+	assert.Equal(t,
+		[]string{"name", "email"},
+		fieldNames(`{"name":"Al","email":"invalid","age":20}`, ValidateOptions{PresentOnly: true}),
+	)
+	// Only present fields are validated, including explicit zero values and nulls
+	present := ValidateOptions{PresentFields: []string{}}
+	assert.Empty(t, fieldNames(`{}`, present))
+	assert.Equal(t, []string{"name", "age"}, fieldNames(`{"name":null,"age":0}`, present))
+	assert.Equal(t, []string{"address.street"}, fieldNames(`{"address":{"city":"Berlin"}}`, present))
+	assert.Equal(t,
+		[]string{`contacts["home"].city`},
+		fieldNames(`{"contacts":{"home":{"street":"Main St"}}}`, present),
+	)
+	// JSON keys are matched case-insensitively like by encoding/json
+	assert.Equal(t, []string{"name"}, fieldNames(`{"Name":"Al"}`, present))
+	assert.Equal(t, []string{"address.street"}, fieldNames(`{"ADDRESS":{"City":"Berlin"}}`, present))
+
+	// Nested fields are validated without their parents
+	patch := UserPatch{Address: &Address{}, Contacts: map[string]Address{"home": {}, "work": {}}}
+	fieldErrors := ValidateStructFieldsWithOptions(nil, patch, ValidateOptions{
+		NamePrefix:    "user.",
+		NameTag:       "json",
+		RulesTag:      "validate",
+		PresentFields: []string{"address.city", "contacts.work.street"},
+	})
+	assert.Equal(t, []string{"user.address.city", `user.contacts["work"].street`}, FieldErrors(fieldErrors).Fields())
+
+	assert.Equal(t,
+		[]string{"age", "address.street"},
+		ZeroValueExportedStructFieldNamesWithOptions(UserPatch{Address: &Address{City: "Berlin"}}, ValidateOptions{
+			NameTag:     "json",
+			PresentOnly: true,
+		}),
+	)
+}
@@ -0,0 +1,530 @@
+package reflection
+
+import (
+	"cmp"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PathSegment is one element of a FieldPath,
+// either the name of a struct field,
+// an index in brackets for slices, arrays, and maps,
+// or a map key in braces that refers to the key itself
+// instead of the value of the map entry.
+type PathSegment struct {
+	// Field is the name of a struct field,
+	// empty for index segments
+	Field string
+	// Index is the text between the brackets of an index segment,
+	// a decimal number for slices and arrays
+	// or a map key that is quoted for string keys
+	Index string
+	// IsIndex is true for index segments in brackets
+	IsIndex bool
+	// IsKey is true for map key segments in braces,
+	// Index holds the formatted key like for index segments
+	IsKey bool
+}
+
+// FieldSegment returns a PathSegment for a struct field name.
+func FieldSegment(name string) PathSegment {
+	return PathSegment{Field: name}
+}
+
+// IndexSegment returns a PathSegment for a slice or array index.
+func IndexSegment(index int) PathSegment {
+	return PathSegment{Index: strconv.Itoa(index), IsIndex: true}
+}
+
+// KeySegment returns a PathSegment for a map key.
+// String keys are quoted, other keys are formatted
+// with encoding.TextMarshaler if implemented
+// or with fmt.Sprint.
+func KeySegment(key reflect.Value) PathSegment {
+	return PathSegment{Index: formatMapKey(key), IsIndex: true}
+}
+
+// MapKeySegment returns a PathSegment that refers to the map key itself
+// instead of the value of its map entry, used for errors of map keys.
+// The key is formatted like with KeySegment.
+func MapKeySegment(key reflect.Value) PathSegment {
+	return PathSegment{Index: formatMapKey(key), IsKey: true}
+}
+
+// String returns the field name, the index in brackets,
+// or the map key in braces.
+func (s PathSegment) String() string {
+	switch {
+	case s.IsIndex:
+		return "[" + s.Index + "]"
+	case s.IsKey:
+		return "{" + s.Index + "}"
+	}
+	return s.Field
+}
+
+// FieldPath is a parsed path to a value nested in structs,
+// slices, arrays, and maps like `Address.Street`, `Items[1].Name`,
+// `Attrs["key"]`, or the map key `Attrs{"key"}`,
+// as returned by ZeroValueExportedStructFieldNames
+// and ValidateStructFields.
+type FieldPath []PathSegment
+
+// ParseFieldPath parses a path of field names separated by dots
+// and index expressions in brackets like `Address.Street`,
+// `Items[1].Name`, `Matrix[1][3]`, or `Attrs["key"]`.
+// A map key in braces like `Attrs{"key"}` refers to the key itself
+// and must be the last segment of the path.
+// Quoted map keys use the Go syntax for quoted strings.
+func ParseFieldPath(path string) (FieldPath, error) {
+	var p FieldPath
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '[', '{':
+			if len(p) > 0 && p[len(p)-1].IsKey {
+				return nil, fmt.Errorf("invalid path %q: map key must be the last segment", path)
+			}
+			end, err := indexEnd(path, i+1)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %w", path, err)
+			}
+			p = append(p, PathSegment{Index: path[i+1 : end], IsIndex: path[i] == '[', IsKey: path[i] == '{'})
+			i = end + 1
+		case '.':
+			if i == 0 || i+1 == len(path) || strings.IndexByte(".[{", path[i+1]) >= 0 || p[len(p)-1].IsKey {
+				return nil, fmt.Errorf("invalid path %q: missing field name at position %d", path, i+1)
+			}
+			i++
+		case ']', '}':
+			return nil, fmt.Errorf("invalid path %q: unexpected %q at position %d", path, path[i], i)
+		default:
+			if i > 0 && (path[i-1] == ']' || path[i-1] == '}') {
+				return nil, fmt.Errorf("invalid path %q: missing '.' at position %d", path, i)
+			}
+			end := i + strings.IndexAny(path[i:], ".[]{}")
+			if end < i {
+				end = len(path)
+			}
+			p = append(p, PathSegment{Field: path[i:end]})
+			i = end
+		}
+	}
+	if len(p) == 0 {
+		return nil, errors.New("empty path")
+	}
+	return p, nil
+}
+
+// indexEnd returns the position of the closing bracket or brace
+// of an index expression starting at start,
+// skipping over quoted strings and nested brackets or braces
+// like in formatted struct keys.
+func indexEnd(path string, start int) (int, error) {
+	opening, closing := path[start-1], byte(']')
+	if opening == '{' {
+		closing = '}'
+	}
+	depth := 0
+	for i := start; i < len(path); i++ {
+		switch path[i] {
+		case opening:
+			depth++
+		case closing:
+			if i == start {
+				return 0, fmt.Errorf("empty index at position %d", start)
+			}
+			if depth > 0 {
+				depth--
+				continue
+			}
+			return i, nil
+		case '"', '`':
+			quoted, err := strconv.QuotedPrefix(path[i:])
+			if err != nil {
+				return 0, fmt.Errorf("invalid quoted map key at position %d: %w", i, err)
+			}
+			i += len(quoted) - 1
+		}
+	}
+	return 0, fmt.Errorf("missing %q for %q at position %d", closing, path[start-1], start-1)
+}
+
+// String returns the path in the format parsed by ParseFieldPath.
+func (p FieldPath) String() string {
+	var b strings.Builder
+	for i, s := range p {
+		if i > 0 && !s.IsIndex && !s.IsKey {
+			b.WriteByte('.')
+		}
+		b.WriteString(s.String())
+	}
+	return b.String()
+}
+
+// LookupPath returns the reflect.Value at path within root.
+// See ParseFieldPath for the path syntax.
+// Struct fields in the path are named by nameTag,
+// which can be a struct tag key string or a *NameResolver,
+// fields of anonymous embedded structs can be used
+// with or without the name of the embedded struct.
+// Pointers and interfaces along the path are dereferenced.
+// A map key segment in braces returns the key of the map entry.
+//
+// The argument root can be a struct, a pointer to a struct,
+// a slice, a map, or a reflect.Value.
+// The returned value is settable if root was passed as pointer
+// and there are no maps along the path.
+//
+// Example:
+//
+//	type Address struct {
+//	    Street string `json:"street"`
+//	}
+//	type Person struct {
+//	    Addresses []Address         `json:"addresses"`
+//	    Attrs     map[string]string `json:"attrs"`
+//	}
+//	p := Person{
+//	    Addresses: []Address{{Street: "Main St"}},
+//	    Attrs:     map[string]string{"key": "value"},
+//	}
+//	v, _ := reflection.LookupPath(p, "addresses[0].street", "json")
+//	fmt.Println(v) // Main St
+//	v, _ = reflection.LookupPath(p, `attrs["key"]`, "json")
+//	fmt.Println(v) // value
+func LookupPath[N NameSource](root any, path string, nameTag N) (reflect.Value, error) {
+	p, err := ParseFieldPath(path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return lookupPath(ValueOf(root), p, nameResolverOf(nameTag))
+}
+
+// GetByPath returns the value at path within root as interface.
+// See LookupPath for the arguments.
+func GetByPath[N NameSource](root any, path string, nameTag N) (any, error) {
+	v, err := LookupPath(root, path, nameTag)
+	if err != nil {
+		return nil, err
+	}
+	if !v.CanInterface() {
+		return nil, fmt.Errorf("value at path %q can't be used as interface", path)
+	}
+	return v.Interface(), nil
+}
+
+// SetByPath sets the value at path within root.
+// See LookupPath for the arguments, root has to be passed as pointer.
+//
+// Nil pointers, maps, and embedded struct pointers along the path
+// are allocated. Map entries are set with the modified copy of their value.
+// The value has to be assignable or convertible to the type at path,
+// or assignable to the element type if the type at path is a pointer.
+// A nil value sets the zero value of the type at path.
+//
+// Example:
+//
+//	var p Person
+//	err := reflection.SetByPath(&p, `attrs["key"]`, "json", "value")
+//	fmt.Println(p.Attrs["key"], err) // value <nil>
+func SetByPath[N NameSource](root any, path string, nameTag N, value any) error {
+	p, err := ParseFieldPath(path)
+	if err != nil {
+		return err
+	}
+	v := ValueOf(root)
+	if !v.IsValid() {
+		return errors.New("SetByPath expects non nil pointer root, but got: nil")
+	}
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("SetByPath expects non nil pointer root, but got: %s", v.Type())
+	}
+	return setPath(v.Elem(), p, nameResolverOf(nameTag), reflect.ValueOf(value))
+}
+
+// errNilPath is wrapped by the errors of lookupPath
+// for nil pointers and interfaces along the path.
+var errNilPath = errors.New("nil")
+
+func lookupPath(v reflect.Value, p FieldPath, r *NameResolver) (reflect.Value, error) {
+	for i, s := range p {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, fmt.Errorf("%w %s at path %q", errNilPath, v.Type(), p[:i])
+			}
+			v = v.Elem()
+		}
+		var err error
+		v, err = pathSegmentValue(v, s, r, NilEmbeddedInvalid)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w at path %q", err, p[:i+1])
+		}
+	}
+	return v, nil
+}
+
+func setPath(v reflect.Value, p FieldPath, r *NameResolver, value reflect.Value) error {
+	for i, s := range p {
+		if s.IsKey {
+			return fmt.Errorf("can't set map key at path %q", p[:i+1])
+		}
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return fmt.Errorf("can't allocate nil %s at path %q", v.Type(), p[:i])
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Map && s.IsIndex {
+			return setMapEntry(v, p, i, r, value)
+		}
+		var err error
+		v, err = pathSegmentValue(v, s, r, NilEmbeddedAlloc)
+		if err != nil {
+			return fmt.Errorf("%w at path %q", err, p[:i+1])
+		}
+	}
+	if err := assignValue(v, value); err != nil {
+		return fmt.Errorf("%w at path %q", err, p)
+	}
+	return nil
+}
+
+// setMapEntry sets the rest of the path p after the segment at i
+// on a copy of the map entry of that segment
+// and stores the copy in the map.
+func setMapEntry(m reflect.Value, p FieldPath, i int, r *NameResolver, value reflect.Value) error {
+	key, err := parseMapKey(p[i].Index, m.Type().Key())
+	if err != nil {
+		return fmt.Errorf("%w at path %q", err, p[:i+1])
+	}
+	if m.IsNil() {
+		if !m.CanSet() {
+			return fmt.Errorf("can't allocate nil %s at path %q", m.Type(), p[:i])
+		}
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	entry := reflect.New(m.Type().Elem()).Elem()
+	if existing := m.MapIndex(key); existing.IsValid() {
+		entry.Set(existing)
+	}
+	if i+1 == len(p) {
+		err = assignValue(entry, value)
+		if err != nil {
+			return fmt.Errorf("%w at path %q", err, p)
+		}
+	} else {
+		err = setPath(entry, p[i+1:], r, value)
+		if err != nil {
+			return fmt.Errorf("%w in map entry at path %q", err, p[:i+1])
+		}
+	}
+	m.SetMapIndex(key, entry)
+	return nil
+}
+
+// pathSegmentValue returns the value for the path segment s
+// of the dereferenced value v.
+func pathSegmentValue(v reflect.Value, s PathSegment, r *NameResolver, nilEmbedded NilEmbeddedMode) (reflect.Value, error) {
+	if s.IsKey {
+		if v.Kind() != reflect.Map {
+			return reflect.Value{}, fmt.Errorf("can't get map key {%s} of %s", s.Index, v.Type())
+		}
+		key, err := parseMapKey(s.Index, v.Type().Key())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !v.MapIndex(key).IsValid() {
+			return reflect.Value{}, fmt.Errorf("no map key {%s}", s.Index)
+		}
+		return key, nil
+	}
+	if !s.IsIndex {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("can't get field %q of %s", s.Field, v.Type())
+		}
+		index, ok := structFieldIndexByName(v.Type(), s.Field, r)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("no field %q in %s", s.Field, v.Type())
+		}
+		fv, ok := flatFieldValue(v, index, nilEmbedded)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("nil embedded struct pointer for field %q", s.Field)
+		}
+		return fv, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.String:
+		index, err := strconv.Atoi(s.Index)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid index [%s] for %s", s.Index, v.Type())
+		}
+		if index < 0 || index >= v.Len() {
+			return reflect.Value{}, fmt.Errorf("index [%d] out of range for %s of length %d", index, v.Type(), v.Len())
+		}
+		return v.Index(index), nil
+
+	case reflect.Map:
+		key, err := parseMapKey(s.Index, v.Type().Key())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		entry := v.MapIndex(key)
+		if !entry.IsValid() {
+			return reflect.Value{}, fmt.Errorf("no map key [%s]", s.Index)
+		}
+		return entry, nil
+	}
+	return reflect.Value{}, fmt.Errorf("can't index %s with [%s]", v.Type(), s.Index)
+}
+
+// structFieldIndexByName returns the index path of the exported field
+// with the name resolved by r from the flattened fields of the struct type t
+// or the direct fields, which includes the fields of embedded structs.
+func structFieldIndexByName(t reflect.Type, name string, r *NameResolver) ([]int, bool) {
+	info := getStructTypeInfo(t)
+	tags := info.tagInfo(r)
+	for i := range tags.flatFields {
+		if n, valid := exportedFieldName(tags.flatFields[i].Field, tags.flatTags[i]); valid && n == name {
+			return tags.flatFields[i].Index, true
+		}
+	}
+	for i := range info.fields {
+		if n, valid := exportedFieldName(info.fields[i].Field, tags.fields[i]); valid && n == name {
+			return info.fields[i].Index, true
+		}
+	}
+	return nil, false
+}
+
+// assignValue sets dst to value converting value if necessary.
+func assignValue(dst, value reflect.Value) error {
+	if !dst.CanSet() {
+		return fmt.Errorf("can't set %s", dst.Type())
+	}
+	switch {
+	case !value.IsValid():
+		dst.SetZero()
+	case value.Type().AssignableTo(dst.Type()):
+		dst.Set(value)
+	case dst.Kind() == reflect.Ptr && value.Type().AssignableTo(dst.Type().Elem()):
+		ptr := reflect.New(dst.Type().Elem())
+		ptr.Elem().Set(value)
+		dst.Set(ptr)
+	case value.Type().ConvertibleTo(dst.Type()) && (dst.Kind() != reflect.String || value.Kind() == reflect.String):
+		dst.Set(value.Convert(dst.Type()))
+	default:
+		return fmt.Errorf("can't assign %s to %s", value.Type(), dst.Type())
+	}
+	return nil
+}
+
+// formatMapKey formats a map key for an index PathSegment.
+// String keys are quoted, other keys are formatted
+// with encoding.TextMarshaler if implemented
+// or with fmt.Sprint.
+func formatMapKey(key reflect.Value) string {
+	if key.Kind() == reflect.Interface && !key.IsNil() {
+		key = key.Elem()
+	}
+	if key.Kind() == reflect.String {
+		return strconv.Quote(key.String())
+	}
+	if m, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(key.Interface())
+}
+
+// parseMapKey parses a map key formatted by formatMapKey
+// into a value of keyType.
+func parseMapKey(s string, keyType reflect.Type) (reflect.Value, error) {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	key := reflect.New(keyType).Elem()
+	if u, ok := key.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid map key [%s] for %s: %w", s, keyType, err)
+		}
+		return key, nil
+	}
+	var err error
+	switch keyType.Kind() {
+	case reflect.String, reflect.Interface:
+		err = assignValue(key, reflect.ValueOf(s))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, keyType.Bits())
+		key.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		u, err = strconv.ParseUint(s, 10, keyType.Bits())
+		key.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(s, keyType.Bits())
+		key.SetFloat(f)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		key.SetBool(b)
+	default:
+		err = errors.ErrUnsupported
+	}
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid map key [%s] for %s: %w", s, keyType, err)
+	}
+	return key, nil
+}
+
+// sortedMapKeys returns the keys of the map m
+// sorted by their value for string, integer, float, and bool keys
+// and by their formatted PathSegment index for other key types.
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	switch m.Type().Key().Kind() {
+	case reflect.String:
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return cmp.Compare(a.String(), b.String()) })
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return cmp.Compare(a.Int(), b.Int()) })
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return cmp.Compare(a.Uint(), b.Uint()) })
+	case reflect.Float32, reflect.Float64:
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return cmp.Compare(a.Float(), b.Float()) })
+	case reflect.Bool:
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			switch {
+			case a.Bool() == b.Bool():
+				return 0
+			case b.Bool():
+				return -1
+			}
+			return 1
+		})
+	default:
+		type formattedKey struct {
+			key       reflect.Value
+			formatted string
+		}
+		formatted := make([]formattedKey, len(keys))
+		for i, key := range keys {
+			formatted[i] = formattedKey{key, formatMapKey(key)}
+		}
+		slices.SortFunc(formatted, func(a, b formattedKey) int { return cmp.Compare(a.formatted, b.formatted) })
+		for i := range formatted {
+			keys[i] = formatted[i].key
+		}
+	}
+	return keys
+}
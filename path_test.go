@@ -0,0 +1,117 @@
+package reflection
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want FieldPath
+	}{
+		{path: "Name", want: FieldPath{FieldSegment("Name")}},
+		{path: "Address.Street", want: FieldPath{FieldSegment("Address"), FieldSegment("Street")}},
+		{path: "Items[1].Name", want: FieldPath{FieldSegment("Items"), IndexSegment(1), FieldSegment("Name")}},
+		{path: "Matrix[1][3]", want: FieldPath{FieldSegment("Matrix"), IndexSegment(1), IndexSegment(3)}},
+		{path: "[4512].Email", want: FieldPath{IndexSegment(4512), FieldSegment("Email")}},
+		{path: `Attrs["a.b[c]\""]`, want: FieldPath{FieldSegment("Attrs"), {Index: `"a.b[c]\""`, IsIndex: true}}},
+		{path: "first-name", want: FieldPath{FieldSegment("first-name")}},
+		{path: `Labels{"env"}`, want: FieldPath{FieldSegment("Labels"), MapKeySegment(reflect.ValueOf("env"))}},
+		{path: "Structs[{1 2}]", want: FieldPath{FieldSegment("Structs"), {Index: "{1 2}", IsIndex: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ParseFieldPath(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.path, got.String())
+		})
+	}
+
+	for _, invalid := range []string{"", ".Name", "Name.", "A..B", "A[0]B", "A[", "A[]", "A]", `A["x]`, "A.[0]", "A{0}.B", "A{0}[1]", "A{}", "A{0]"} {
+		_, err := ParseFieldPath(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestGetAndSetByPath(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+	}
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type Person struct {
+		*Base
+		Name      string             `json:"name"`
+		Address   *Address           `json:"address"`
+		Addresses []Address          `json:"addresses"`
+		Attrs     map[string]string  `json:"attrs"`
+		Scores    map[int]float64    `json:"scores"`
+		Nested    map[string]Address `json:"nested"`
+		Matrix    [][]int            `json:"matrix"`
+	}
+
+	p := Person{
+		Base:      &Base{ID: 1},
+		Name:      "Alice",
+		Addresses: []Address{{Street: "First"}, {Street: "Second"}},
+		Attrs:     map[string]string{"key": "value"},
+		Scores:    map[int]float64{42: 0.5},
+		Matrix:    [][]int{{1, 2}, {3, 4, 5}},
+	}
+
+	for path, want := range map[string]any{
+		"name":                "Alice",
+		"id":                  1,
+		"Base.id":             1,
+		"addresses[1].street": "Second",
+		`attrs["key"]`:        "value",
+		"scores[42]":          0.5,
+		"matrix[1][2]":        5,
+	} {
+		got, err := GetByPath(p, path, "json")
+		assert.NoError(t, err, path)
+		assert.Equal(t, want, got, path)
+	}
+	got, err := GetByPath(p, "Addresses[0].Street", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "First", got)
+
+	key, err := GetByPath(p, "scores{42}", "json")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, key)
+
+	for _, path := range []string{"address.street", "addresses[2]", `attrs["missing"]`, `attrs{"missing"}`, "name{0}", "unknown", "name.x", "scores[x]"} {
+		_, err := GetByPath(p, path, "json")
+		assert.Error(t, err, path)
+	}
+
+	require.NoError(t, SetByPath(&p, "address.street", "json", "Main St"))
+	assert.Equal(t, "Main St", p.Address.Street)
+	require.NoError(t, SetByPath(&p, `attrs["new"]`, "json", "new value"))
+	assert.Equal(t, "new value", p.Attrs["new"])
+	require.NoError(t, SetByPath(&p, `nested["home"].street`, "json", "Home St"))
+	assert.Equal(t, "Home St", p.Nested["home"].Street)
+	require.NoError(t, SetByPath(&p, "scores[7]", "json", 1))
+	assert.Equal(t, 1.0, p.Scores[7])
+	require.NoError(t, SetByPath(&p, "matrix[0][1]", "json", 9))
+	assert.Equal(t, 9, p.Matrix[0][1])
+	require.NoError(t, SetByPath(&p, "name", "json", nil))
+	assert.Equal(t, "", p.Name)
+
+	var empty Person
+	require.NoError(t, SetByPath(&empty, "id", "json", 2))
+	assert.Equal(t, 2, empty.ID)
+
+	assert.Error(t, SetByPath(p, "name", "json", "x"), "not a pointer")
+	assert.Error(t, SetByPath(nil, "name", "json", "x"), "nil root")
+	assert.Error(t, SetByPath((*Person)(nil), "name", "json", "x"), "nil pointer root")
+	assert.Error(t, SetByPath(&p, "name", "json", 1), "int to string")
+	assert.Error(t, SetByPath(&p, "addresses[5].street", "json", "x"), "out of range")
+	assert.Error(t, SetByPath(&p, `attrs{"key"}`, "json", "x"), "map key")
+}
@@ -0,0 +1,596 @@
+package reflection
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidationField is a struct field passed to a ValidationRule,
+// the ValidateOptions.ValidateField function,
+// or the validation function of ValidateStructFieldsContext.
+//
+// For slice, array, and map elements and map keys
+// passed to the validation function of ValidateStructFieldsContext,
+// Name and Value are those of the element
+// and the other fields those of the struct field containing the element.
+type ValidationField struct {
+	Name   string              // Name of the field including the name prefix (e.g., "address.street")
+	GoName string              // Go field names of the field path including the name prefix (e.g., "Address.Street")
+	Field  reflect.StructField // Type information of the field
+	Tag    Tag                 // Parsed tag of the field from the NameResolver used for the name
+	Value  reflect.Value       // Value of the field
+	Parent reflect.Value       // Struct value that contains the field
+	Root   reflect.Value       // Dereferenced struct value passed to the validation function
+
+	names *NameResolver
+	// rules are the cached parsed rules of the field
+	rules *fieldRules
+	// traverseOnly is set for fields that are not selected
+	// but contain selected nested fields
+	traverseOnly bool
+}
+
+// elem returns a copy of the field for an element of the field value
+// with the index or map key segment appended to the names.
+func (f ValidationField) elem(segment string, value reflect.Value) ValidationField {
+	f.Name += segment
+	f.GoName += segment
+	f.Value = value
+	return f
+}
+
+// Sibling returns the value at path relative to the parent struct of the field
+// using the same field names as the validation, see LookupPath.
+// If the path can't be resolved with those names,
+// then the Go field names are tried.
+//
+// Example:
+//
+//	func(field reflection.ValidationField) error {
+//	    password, err := field.Sibling("password")
+//	    ...
+//	}
+func (f ValidationField) Sibling(path string) (reflect.Value, error) {
+	p, err := ParseFieldPath(path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	v, err := lookupPath(f.Parent, p, f.names)
+	if err != nil && !errors.Is(err, errNilPath) {
+		if goNames := tagNameResolver(""); f.names != goNames {
+			if goNamesValue, goNamesErr := lookupPath(f.Parent, p, goNames); goNamesErr == nil {
+				return goNamesValue, nil
+			}
+		}
+	}
+	return v, err
+}
+
+// ValidationRule validates a struct field using the parameter
+// of the rule from the struct tag, like "3" for "min=3"
+// or an empty string for rules without parameter.
+//
+// Rules should return nil for values they don't apply to,
+// like nil pointers, so that they can be combined with
+// the "required" and "omitempty" rules.
+// Invalid parameters are programming errors and should panic.
+type ValidationRule func(field ValidationField, param string) error
+
+// RuleError is the error of a failed ValidationRule
+// returned as FieldError.FieldError.
+//
+// A ValidationRule can return a *RuleError with Args
+// for its message templates, see RegisterValidationMessage.
+// Rule and Param are set by the validation.
+type RuleError struct {
+	Rule  string // Name of the rule (e.g., "min")
+	Param string // Parameter of the rule (e.g., "3")
+	Args  []any  // Additional arguments for message templates (e.g., the sibling field of required_with)
+	Err   error  // Error returned by the rule
+}
+
+// Error implements the error interface and returns the error message of the rule.
+func (e *RuleError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error returned by the rule.
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	validationRulesMtx sync.RWMutex
+	validationRules    = map[string]ValidationRule{
+		"required": ruleRequired,
+		"min":      ruleMin,
+		"max":      ruleMax,
+		"len":      ruleLen,
+		"oneof":    ruleOneOf,
+		"email":    ruleEmail,
+		"url":      ruleURL,
+		"uuid":     ruleUUID,
+		"regexp":   ruleRegexp,
+		"eqfield":  fieldComparisonRule("eqfield", "must be equal to", func(c int) bool { return c == 0 }),
+		"nefield":  fieldComparisonRule("nefield", "must not be equal to", func(c int) bool { return c != 0 }),
+		"gtfield":  fieldComparisonRule("gtfield", "must be greater than", func(c int) bool { return c > 0 }),
+		"gtefield": fieldComparisonRule("gtefield", "must be greater than or equal to", func(c int) bool { return c >= 0 }),
+		"ltfield":  fieldComparisonRule("ltfield", "must be less than", func(c int) bool { return c < 0 }),
+		"ltefield": fieldComparisonRule("ltefield", "must be less than or equal to", func(c int) bool { return c <= 0 }),
+
+		"required_if":      ruleRequiredIf,
+		"required_unless":  ruleRequiredUnless,
+		"required_with":    ruleRequiredWith,
+		"required_without": ruleRequiredWithout,
+	}
+
+	regexpCache sync.Map // string -> *regexp.Regexp
+	uuidRegexp  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// RegisterValidationRule registers a ValidationRule under a name
+// for the use in validation struct tags like `validate:"name=param"`.
+// Built-in rules can be replaced by registering a rule with the same name.
+//
+// The built-in rules are:
+//   - required: the value must not be zero as defined by IsZero
+//   - omitempty: skips the following rules if the value is zero (can't be replaced)
+//   - min=N, max=N, len=N: the minimum, maximum, or exact number of characters of strings,
+//     number of elements of slices, arrays, and maps, or value of numbers (min and max only)
+//   - oneof=a b c: the value formatted with fmt.Sprint must be one of the space separated values
+//   - email: the string must be an email address without display name
+//   - url: the string must be an absolute URL with scheme and host
+//   - uuid: the string must be a UUID in the canonical 8-4-4-4-12 hex format
+//   - regexp=pattern: the string must match the regular expression,
+//     quote the pattern if it contains commas like regexp='^[a-z]{1,8}$'
+//   - eqfield=Path, nefield=Path: the value must be equal or not equal to the sibling field at Path
+//   - gtfield=Path, gtefield=Path, ltfield=Path, ltefield=Path: the value must be greater than,
+//     greater than or equal to, less than, or less than or equal to the sibling field at Path.
+//     Numbers, strings, and types with a Compare method like time.Time can be compared.
+//
+// Conditional rules check if the value is not zero as defined by IsZero
+// depending on sibling fields:
+//   - required_if=Path a b c: required if the sibling field at Path is one of the space separated values
+//   - required_unless=Path a b c: required unless the sibling field at Path is one of the values
+//   - required_with=Path1 Path2: required if any of the sibling fields is not zero
+//   - required_without=Path1 Path2: required if any of the sibling fields is zero
+//
+// Put omitempty after a conditional rule to skip the remaining rules
+// for zero values that are not required, like in
+// `validate:"required_if=Country DE AT,omitempty,len=11"`.
+//
+// Sibling fields are referenced by a path relative to the parent struct
+// using the same field names as the validation or the Go field names,
+// see ValidationField.Sibling.
+//
+// Use RegisterValidationMessage to register localized messages for a rule.
+//
+// Example:
+//
+//	reflection.RegisterValidationRule("even", func(field reflection.ValidationField, param string) error {
+//	    if field.Value.CanInt() && field.Value.Int()%2 != 0 {
+//	        return errors.New("must be even")
+//	    }
+//	    return nil
+//	})
+//
+//	type Pair struct {
+//	    Count int `validate:"even"`
+//	}
+func RegisterValidationRule(name string, rule ValidationRule) {
+	if name == "" || name == "omitempty" || strings.ContainsAny(name, ",= ") {
+		panic(fmt.Errorf("invalid validation rule name %q", name))
+	}
+	if rule == nil {
+		panic(fmt.Errorf("nil validation rule %q", name))
+	}
+	validationRulesMtx.Lock()
+	defer validationRulesMtx.Unlock()
+
+	validationRules[name] = rule
+}
+
+func validationRule(name string) ValidationRule {
+	validationRulesMtx.RLock()
+	defer validationRulesMtx.RUnlock()
+
+	return validationRules[name]
+}
+
+// ValidateStructRules validates all exported fields of a struct
+// with the declarative rules of their `validate` struct tags
+// like `validate:"required,min=3,max=64"`.
+// See RegisterValidationRule for the built-in rules.
+//
+// Fields are traversed like with ValidateStructFields,
+// so the rules of the fields of sub-structs, pointers to sub-structs,
+// slice and array elements, and map values are also checked.
+// For every field only the error of the first failed rule is returned
+// as FieldError with a *RuleError.
+// Unknown rule names panic.
+//
+// Use ValidateStructFieldsWithOptions with ValidateOptions.RulesTag
+// to combine rules with a custom validation function
+// or to use a different struct tag key.
+//
+// Example:
+//
+//	type SignUp struct {
+//	    Name  string `json:"name" validate:"required,min=3,max=64"`
+//	    Email string `json:"email" validate:"required,email"`
+//	    Plan  string `json:"plan" validate:"omitempty,oneof=free pro"`
+//	}
+//
+//	errs := reflection.ValidateStructRules(SignUp{Name: "Al", Email: "al@example.com"}, "", "json")
+//	// errs: [FieldError{FieldName: "name", FieldError: &RuleError{Rule: "min", Param: "3", ...}}]
+func ValidateStructRules[N NameSource](st any, namePrefix string, nameTag N, namesToValidate ...string) []FieldError {
+	return ValidateStructFieldsWithOptions(nil, st, ValidateOptions{
+		NamePrefix:      namePrefix,
+		NameResolver:    nameResolverOf(nameTag),
+		NamesToValidate: namesToValidate,
+		RulesTag:        "validate",
+	})
+}
+
+// validateRules checks the rules of the struct tag rulesTag of a field
+// and returns the *RuleError of the first failed rule.
+func validateRules(field ValidationField, rulesTag string) error {
+	var rules TagOptions
+	if field.rules != nil {
+		rules = field.rules.options(field.Field, rulesTag)
+	} else {
+		rules = ParseTagOptions(field.Field.Tag.Get(rulesTag))
+	}
+	for _, option := range rules {
+		if option.Key == "omitempty" {
+			if isZeroValue(field.Value) {
+				return nil
+			}
+			continue
+		}
+		rule := validationRule(option.Key)
+		if rule == nil {
+			panic(fmt.Errorf("unknown validation rule %q in tag of field %s", option.Key, field.Name))
+		}
+		err := rule(field, option.Value)
+		if err != nil {
+			if ruleErr, ok := err.(*RuleError); ok {
+				return &RuleError{Rule: option.Key, Param: option.Value, Args: ruleErr.Args, Err: ruleErr.Err}
+			}
+			return &RuleError{Rule: option.Key, Param: option.Value, Err: err}
+		}
+	}
+	return nil
+}
+
+func isZeroValue(v reflect.Value) bool {
+	return !v.IsValid() || !v.CanInterface() || IsZero(v.Interface())
+}
+
+// ruleValue returns the value of the field with pointers dereferenced
+// or false if there is a nil pointer.
+func ruleValue(field ValidationField) (reflect.Value, bool) {
+	v := field.Value
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
+
+// ruleString returns the string of the dereferenced field value
+// or false if there is a nil pointer.
+// Panics if the field is not a string.
+func ruleString(field ValidationField, rule string) (string, bool) {
+	v, ok := ruleValue(field)
+	if !ok {
+		return "", false
+	}
+	if v.Kind() != reflect.String {
+		panic(fmt.Errorf("validation rule %q can't be used for field %s of type %s", rule, field.Name, field.Field.Type))
+	}
+	return v.String(), true
+}
+
+// ruleSize returns the number of characters of strings,
+// the length of slices, arrays, and maps,
+// or the value of numbers together with the unit of the size
+// for error messages.
+func ruleSize(field ValidationField, rule string, numbers bool) (size float64, unit string, ok bool) {
+	v, ok := ruleValue(field)
+	if !ok {
+		return 0, "", false
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), " elements", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if numbers {
+			return float64(v.Int()), "", true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if numbers {
+			return float64(v.Uint()), "", true
+		}
+	case reflect.Float32, reflect.Float64:
+		if numbers {
+			return v.Float(), "", true
+		}
+	}
+	panic(fmt.Errorf("validation rule %q can't be used for field %s of type %s", rule, field.Name, field.Field.Type))
+}
+
+func ruleParamFloat(rule, param string) float64 {
+	f, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Errorf("invalid parameter for validation rule %q: %w", rule, err))
+	}
+	return f
+}
+
+func ruleRequired(field ValidationField, _ string) error {
+	if isZeroValue(field.Value) {
+		return errors.New("is required")
+	}
+	return nil
+}
+
+func ruleMin(field ValidationField, param string) error {
+	limit := ruleParamFloat("min", param)
+	size, unit, ok := ruleSize(field, "min", true)
+	if ok && size < limit {
+		return fmt.Errorf("must have at least %s%s", param, unit)
+	}
+	return nil
+}
+
+func ruleMax(field ValidationField, param string) error {
+	limit := ruleParamFloat("max", param)
+	size, unit, ok := ruleSize(field, "max", true)
+	if ok && size > limit {
+		return fmt.Errorf("must have at most %s%s", param, unit)
+	}
+	return nil
+}
+
+func ruleLen(field ValidationField, param string) error {
+	length := ruleParamFloat("len", param)
+	size, unit, ok := ruleSize(field, "len", false)
+	if ok && size != length {
+		return fmt.Errorf("must have exactly %s%s", param, unit)
+	}
+	return nil
+}
+
+func ruleOneOf(field ValidationField, param string) error {
+	v, ok := ruleValue(field)
+	if !ok {
+		return nil
+	}
+	values := strings.Fields(param)
+	for _, value := range values {
+		if fmt.Sprint(v.Interface()) == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(values, ", "))
+}
+
+func ruleEmail(field ValidationField, _ string) error {
+	s, ok := ruleString(field, "email")
+	if !ok {
+		return nil
+	}
+	if addr, err := mail.ParseAddress(s); err != nil || addr.Name != "" || addr.Address != s {
+		return errors.New("must be a valid email address")
+	}
+	return nil
+}
+
+func ruleURL(field ValidationField, _ string) error {
+	s, ok := ruleString(field, "url")
+	if !ok {
+		return nil
+	}
+	if u, err := url.Parse(s); err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.New("must be a valid URL")
+	}
+	return nil
+}
+
+func ruleUUID(field ValidationField, _ string) error {
+	s, ok := ruleString(field, "uuid")
+	if ok && !uuidRegexp.MatchString(s) {
+		return errors.New("must be a valid UUID")
+	}
+	return nil
+}
+
+func ruleRegexp(field ValidationField, param string) error {
+	s, ok := ruleString(field, "regexp")
+	if !ok {
+		return nil
+	}
+	re, cached := regexpCache.Load(param)
+	if !cached {
+		compiled, err := regexp.Compile(param)
+		if err != nil {
+			panic(fmt.Errorf("invalid parameter for validation rule \"regexp\": %w", err))
+		}
+		re, _ = regexpCache.LoadOrStore(param, compiled)
+	}
+	if !re.(*regexp.Regexp).MatchString(s) {
+		return fmt.Errorf("must match the pattern %s", param)
+	}
+	return nil
+}
+
+// fieldComparisonRule returns a ValidationRule that compares the field value
+// with the value of the sibling field at the path of the rule parameter
+// and fails if valid returns false for the result of compareValues.
+// Values of the same type that can't be ordered can still be
+// checked for equality.
+func fieldComparisonRule(rule, message string, valid func(c int) bool) ValidationRule {
+	return func(field ValidationField, param string) error {
+		v, ok := ruleValue(field)
+		if !ok {
+			return nil
+		}
+		other := ruleSibling(field, rule, param)
+		if !other.IsValid() {
+			return nil
+		}
+		c, ordered := compareValues(v, other)
+		if !ordered {
+			equality := rule == "eqfield" || rule == "nefield"
+			if !equality || v.Type() != other.Type() || !v.CanInterface() || !other.CanInterface() {
+				panic(fmt.Errorf("validation rule %q can't compare field %s of type %s with %s", rule, field.Name, v.Type(), other.Type()))
+			}
+			c = 1
+			if reflect.DeepEqual(v.Interface(), other.Interface()) {
+				c = 0
+			}
+		}
+		if !valid(c) {
+			return fmt.Errorf("%s %s", message, param)
+		}
+		return nil
+	}
+}
+
+// compareValues compares numbers of any numeric kind, strings,
+// and values of the same type with a method Compare(T) int like time.Time.
+// Returns false if the values can't be compared.
+func compareValues(a, b reflect.Value) (int, bool) {
+	switch {
+	case a.CanInt() && b.CanInt():
+		return cmp.Compare(a.Int(), b.Int()), true
+	case a.CanUint() && b.CanUint():
+		return cmp.Compare(a.Uint(), b.Uint()), true
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+	}
+	if af, ok := numberAsFloat(a); ok {
+		if bf, ok := numberAsFloat(b); ok {
+			return cmp.Compare(af, bf), true
+		}
+	}
+	if a.Type() == b.Type() && a.CanInterface() {
+		m := a.MethodByName("Compare")
+		if m.IsValid() && m.Type().NumIn() == 1 && m.Type().In(0) == b.Type() &&
+			m.Type().NumOut() == 1 && m.Type().Out(0).Kind() == reflect.Int {
+			return int(m.Call([]reflect.Value{b})[0].Int()), true
+		}
+	}
+	return 0, false
+}
+
+func numberAsFloat(v reflect.Value) (float64, bool) {
+	switch {
+	case v.CanInt():
+		return float64(v.Int()), true
+	case v.CanUint():
+		return float64(v.Uint()), true
+	case v.CanFloat():
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// ruleSibling returns the dereferenced value of the sibling field at path
+// or an invalid reflect.Value if there is a nil pointer along the path.
+// Panics if there is no such field.
+func ruleSibling(field ValidationField, rule, path string) reflect.Value {
+	v, _ := ruleValue(ValidationField{Value: ruleSiblingValue(field, rule, path)})
+	return v
+}
+
+// ruleSiblingValue returns the value of the sibling field at path
+// without dereferencing it, so that a non nil pointer to a zero value
+// counts as set like with IsZero,
+// or an invalid reflect.Value if there is a nil pointer along the path.
+// Panics if there is no such field.
+func ruleSiblingValue(field ValidationField, rule, path string) reflect.Value {
+	v, err := field.Sibling(path)
+	if errors.Is(err, errNilPath) {
+		return reflect.Value{}
+	}
+	if err != nil {
+		panic(fmt.Errorf("invalid parameter for validation rule %q of field %s: %w", rule, field.Name, err))
+	}
+	return v
+}
+
+// siblingIsOneOf returns if the sibling field at the path of the first word
+// of param formatted with fmt.Sprint is one of the other words of param.
+func siblingIsOneOf(field ValidationField, rule, param string) (path string, values []string, isOneOf bool) {
+	words := strings.Fields(param)
+	if len(words) < 2 {
+		panic(fmt.Errorf("validation rule %q of field %s needs a field and values as parameter", rule, field.Name))
+	}
+	path, values = words[0], words[1:]
+	sibling := ruleSibling(field, rule, path)
+	if !sibling.IsValid() || !sibling.CanInterface() {
+		return path, values, false
+	}
+	formatted := fmt.Sprint(sibling.Interface())
+	for _, value := range values {
+		if formatted == value {
+			return path, values, true
+		}
+	}
+	return path, values, false
+}
+
+func ruleRequiredIf(field ValidationField, param string) error {
+	path, values, required := siblingIsOneOf(field, "required_if", param)
+	if required && isZeroValue(field.Value) {
+		return fmt.Errorf("is required if %s is %s", path, strings.Join(values, " or "))
+	}
+	return nil
+}
+
+func ruleRequiredUnless(field ValidationField, param string) error {
+	path, values, notRequired := siblingIsOneOf(field, "required_unless", param)
+	if !notRequired && isZeroValue(field.Value) {
+		return fmt.Errorf("is required unless %s is %s", path, strings.Join(values, " or "))
+	}
+	return nil
+}
+
+func ruleRequiredWith(field ValidationField, param string) error {
+	if !isZeroValue(field.Value) {
+		return nil
+	}
+	for _, path := range strings.Fields(param) {
+		if !isZeroValue(ruleSiblingValue(field, "required_with", path)) {
+			return &RuleError{Args: []any{path}, Err: fmt.Errorf("is required if %s is set", path)}
+		}
+	}
+	return nil
+}
+
+func ruleRequiredWithout(field ValidationField, param string) error {
+	if !isZeroValue(field.Value) {
+		return nil
+	}
+	for _, path := range strings.Fields(param) {
+		if isZeroValue(ruleSiblingValue(field, "required_without", path)) {
+			return &RuleError{Args: []any{path}, Err: fmt.Errorf("is required if %s is not set", path)}
+		}
+	}
+	return nil
+}
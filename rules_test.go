@@ -0,0 +1,325 @@
+package reflection
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateStructRules(t *testing.T) {
+	type Address struct {
+		Street string `json:"street" validate:"required"`
+		Zip    string `json:"zip" validate:"omitempty,regexp='^[0-9]{5}$'"`
+	}
+	type SignUp struct {
+		Name      string            `json:"name" validate:"required,min=3,max=8"`
+		Email     string            `json:"email" validate:"required,email"`
+		Website   *string           `json:"website" validate:"omitempty,url"`
+		ID        string            `json:"id" validate:"uuid"`
+		Plan      string            `json:"plan" validate:"omitempty,oneof=free pro"`
+		Age       int               `json:"age" validate:"min=18,max=130"`
+		Score     float64           `json:"score" validate:"max=1.5"`
+		Tags      []string          `json:"tags" validate:"min=1,max=2"`
+		Code      string            `json:"code" validate:"len=4"`
+		Attrs     map[string]string `json:"attrs" validate:"omitempty,len=1"`
+		Addresses []Address         `json:"addresses"`
+		Untagged  string            `json:"untagged"`
+	}
+
+	valid := SignUp{
+		Name:      "Alice",
+		Email:     "alice@example.com",
+		ID:        "123e4567-e89b-12d3-a456-426614174000",
+		Plan:      "pro",
+		Age:       30,
+		Score:     1.5,
+		Tags:      []string{"a"},
+		Code:      "äöüß",
+		Addresses: []Address{{Street: "Main St", Zip: "12345"}},
+	}
+	assert.Empty(t, ValidateStructRules(valid, "", "json"))
+
+	website := "example.com"
+	invalid := SignUp{
+		Name:      "Al",
+		Email:     "Alice <alice@example.com>",
+		Website:   &website,
+		ID:        "123e4567",
+		Plan:      "enterprise",
+		Age:       17,
+		Score:     2,
+		Tags:      []string{"a", "b", "c"},
+		Code:      "abc",
+		Attrs:     map[string]string{"a": "", "b": ""},
+		Addresses: []Address{{Zip: "1234"}},
+	}
+	fieldErrors := ValidateStructRules(invalid, "", "json")
+	var rules []string
+	for _, fieldErr := range fieldErrors {
+		var ruleErr *RuleError
+		require.ErrorAs(t, fieldErr.FieldError, &ruleErr)
+		rules = append(rules, fieldErr.FieldName+":"+ruleErr.Rule)
+	}
+	assert.Equal(t, []string{
+		"name:min",
+		"email:email",
+		"website:url",
+		"id:uuid",
+		"plan:oneof",
+		"age:min",
+		"score:max",
+		"tags:max",
+		"code:len",
+		"attrs:len",
+		"addresses[0].street:required",
+		"addresses[0].zip:regexp",
+	}, rules)
+	assert.Equal(t, "name: must have at least 3 characters", fieldErrors[0].Error())
+	assert.Equal(t, "plan: must be one of free, pro", fieldErrors[4].Error())
+	assert.Equal(t, "age: must have at least 18", fieldErrors[5].Error())
+
+	fieldErrors = ValidateStructRules(SignUp{}, "", "json", "name", "email")
+	if assert.Len(t, fieldErrors, 2) {
+		assert.Equal(t, "name: is required", fieldErrors[0].Error())
+		assert.Equal(t, "email: is required", fieldErrors[1].Error())
+	}
+
+	// Rules of the fields of struct pointers are checked like zero values are detected
+	type Person struct {
+		Home *Address `json:"home"`
+		Work *Address `json:"work"`
+	}
+	person := Person{Home: &Address{Zip: "1"}}
+	assert.Equal(t, []string{"home.street", "home.zip"}, FieldErrors(ValidateStructRules(person, "", "json")).Fields())
+	assert.Equal(t, []string{"home.street", "home.zip", "work"}, ZeroValueExportedStructFieldNames(Person{Home: &Address{}}, "", "json"))
+
+	assert.Panics(t, func() {
+		type Unknown struct {
+			Value string `validate:"unknown"`
+		}
+		ValidateStructRules(Unknown{}, "", "")
+	})
+	assert.Panics(t, func() {
+		type WrongType struct {
+			Value int `validate:"email"`
+		}
+		ValidateStructRules(WrongType{}, "", "")
+	})
+}
+
+func TestValidateStructRulesCache(t *testing.T) {
+	type Base struct {
+		ID string `validate:"required" check:"min=3"`
+	}
+	type Item struct {
+		Base
+		Name string `validate:"min=3"`
+	}
+	assert.Len(t, ValidateStructRules(Item{}, "", ""), 2)
+	assert.Len(t, ValidateStructFieldsWithOptions(nil, Item{Base: Base{ID: "1"}}, ValidateOptions{RulesTag: "check"}), 1)
+
+	// The parsed rules are cached per field and rules tag,
+	// also for the flattened fields of embedded structs
+	rules := getStructTypeInfo(reflect.TypeFor[Base]()).fields[0].Rules
+	assert.Equal(t, TagOptions{{Key: "required"}}, rules.options(reflect.StructField{}, "validate"))
+	assert.Equal(t, TagOptions{{Key: "min", Value: "3", HasValue: true}}, rules.options(reflect.StructField{}, "check"))
+}
+
+func TestRegisterValidationRule(t *testing.T) {
+	errOdd := errors.New("must be even")
+	RegisterValidationRule("test_even", func(field ValidationField, param string) error {
+		if field.Value.Int()%2 != 0 {
+			return errOdd
+		}
+		return nil
+	})
+	assert.Panics(t, func() { RegisterValidationRule("omitempty", nil) })
+	assert.Panics(t, func() { RegisterValidationRule("a,b", ruleRequired) })
+
+	type Pair struct {
+		Count int    `rules:"test_even"`
+		Name  string `rules:"required"`
+	}
+	errNotEmpty := errors.New("not empty")
+	validateFunc := func(v any) error {
+		if s, ok := v.(string); ok && s != "" {
+			return errNotEmpty
+		}
+		return nil
+	}
+
+	// Rules and the validation function are combined
+	fieldErrors := ValidateStructFieldsWithOptions(validateFunc, Pair{Count: 3, Name: "Name"}, ValidateOptions{RulesTag: "rules"})
+	if assert.Len(t, fieldErrors, 2) {
+		assert.Equal(t, "Count", fieldErrors[0].FieldName)
+		assert.ErrorIs(t, fieldErrors[0].FieldError, errOdd)
+		assert.Equal(t, "Name", fieldErrors[1].FieldName)
+		assert.ErrorIs(t, fieldErrors[1].FieldError, errNotEmpty)
+	}
+}
+
+func TestValidateCrossFieldRules(t *testing.T) {
+	type Period struct {
+		StartDate time.Time  `json:"startDate"`
+		EndDate   *time.Time `json:"endDate" validate:"gtfield=startDate"`
+	}
+	type Account struct {
+		Password        string   `json:"password" validate:"required"`
+		ConfirmPassword string   `json:"confirmPassword" validate:"eqfield=password"`
+		Username        string   `json:"username" validate:"nefield=password"`
+		MinAge          int      `json:"minAge"`
+		MaxAge          uint8    `json:"maxAge" validate:"gtefield=minAge"`
+		Limit           float64  `json:"limit" validate:"ltefield=maxAge"`
+		Roles           []string `json:"roles"`
+		Admins          []string `json:"admins" validate:"nefield=roles"`
+		Period          Period   `json:"period"`
+		Periods         []Period `json:"periods"`
+	}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	before := start.Add(-time.Hour)
+	valid := Account{
+		Password:        "secret",
+		ConfirmPassword: "secret",
+		Username:        "user",
+		MinAge:          18,
+		MaxAge:          18,
+		Limit:           17.5,
+		Roles:           []string{"user"},
+		Admins:          []string{"admin"},
+		Period:          Period{StartDate: start, EndDate: &end},
+		Periods:         []Period{{StartDate: start}},
+	}
+	assert.Empty(t, ValidateStructRules(valid, "", "json"))
+
+	invalid := Account{
+		Password:        "secret",
+		ConfirmPassword: "public",
+		Username:        "secret",
+		MinAge:          18,
+		MaxAge:          17,
+		Limit:           17.5,
+		Roles:           []string{"user"},
+		Admins:          []string{"user"},
+		Period:          Period{StartDate: start, EndDate: &start},
+		Periods:         []Period{{StartDate: start, EndDate: &before}},
+	}
+	var messages []string
+	for _, fieldErr := range ValidateStructRules(invalid, "", "json") {
+		messages = append(messages, fieldErr.Error())
+	}
+	assert.Equal(t, []string{
+		"confirmPassword: must be equal to password",
+		"username: must not be equal to password",
+		"maxAge: must be greater than or equal to minAge",
+		"limit: must be less than or equal to maxAge",
+		"admins: must not be equal to roles",
+		"period.endDate: must be greater than startDate",
+		"periods[0].endDate: must be greater than startDate",
+	}, messages)
+
+	assert.Panics(t, func() {
+		type Unknown struct {
+			Value string `validate:"eqfield=Missing"`
+		}
+		ValidateStructRules(Unknown{}, "", "")
+	})
+	assert.Panics(t, func() {
+		type Incomparable struct {
+			A int
+			B string `validate:"ltfield=A"`
+		}
+		ValidateStructRules(Incomparable{}, "", "")
+	})
+}
+
+func TestValidateFieldFunc(t *testing.T) {
+	type Range struct {
+		Min int
+		Max int
+	}
+	type Config struct {
+		Range
+		Name string
+	}
+	cfg := &Config{Range: Range{Min: 5, Max: 1}, Name: "config"}
+
+	var fields []string
+	errMax := errors.New("must not be less than Min")
+	fieldErrors := ValidateStructFieldsWithOptions(nil, cfg, ValidateOptions{
+		ValidateField: func(field ValidationField) error {
+			fields = append(fields, field.Name)
+			assert.Equal(t, reflect.ValueOf(cfg).Elem().Interface(), field.Root.Interface())
+			assert.Equal(t, field.Root.Interface(), field.Parent.Interface())
+			if field.Name != "Max" {
+				return nil
+			}
+			minValue, err := field.Sibling("Min")
+			require.NoError(t, err)
+			if field.Value.Int() < minValue.Int() {
+				return errMax
+			}
+			return nil
+		},
+	})
+	assert.Equal(t, []string{"Min", "Max", "Name"}, fields)
+	assert.Equal(t, []nameError{{"Max", errMax}}, nameErrors(fieldErrors))
+}
+
+func TestValidateConditionalRules(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+	}
+	type Customer struct {
+		Country   string   `json:"country"`
+		VATNumber string   `json:"vatNumber" validate:"required_if=country DE AT FR,omitempty,min=8"`
+		TaxID     string   `json:"taxId" validate:"required_unless=Country US"`
+		Phone     string   `json:"phone"`
+		PhoneType string   `json:"phoneType" validate:"required_with=phone"`
+		Email     *string  `json:"email" validate:"required_without=phone address.street"`
+		Address   *Address `json:"address"`
+	}
+
+	fieldMessages := func(c Customer) (messages []string) {
+		for _, fieldErr := range ValidateStructRules(c, "", "json") {
+			messages = append(messages, fieldErr.Error())
+		}
+		return messages
+	}
+
+	assert.Equal(t, []string{
+		"vatNumber: is required if country is DE or AT or FR",
+		"taxId: is required unless Country is US",
+		"email: is required if phone is not set",
+	}, fieldMessages(Customer{Country: "DE"}))
+
+	email := "mail@example.com"
+	assert.Empty(t, fieldMessages(Customer{Country: "US", Email: &email}))
+	assert.Equal(t,
+		[]string{"vatNumber: must have at least 8 characters"},
+		fieldMessages(Customer{Country: "AT", VATNumber: "ATU1", TaxID: "1", Email: &email}),
+	)
+	assert.Equal(t,
+		[]string{"phoneType: is required if phone is set", "email: is required if address.street is not set"},
+		fieldMessages(Customer{Country: "US", Phone: "123", Address: &Address{}}),
+	)
+	assert.Empty(t, fieldMessages(Customer{Country: "US", Phone: "123", PhoneType: "mobile", Address: &Address{Street: "Main St"}}))
+
+	// A non nil pointer to a zero value counts as set like with IsZero
+	type Patch struct {
+		Quantity *int   `json:"quantity"`
+		Unit     string `json:"unit" validate:"required_with=quantity"`
+		Price    *int   `json:"price" validate:"required_without=quantity"`
+	}
+	zero := 0
+	patchFields := func(p Patch) []string {
+		return FieldErrors(ValidateStructRules(p, "", "json")).Fields()
+	}
+	assert.Equal(t, []string{"unit"}, patchFields(Patch{Quantity: &zero}))
+	assert.Equal(t, []string{"price"}, patchFields(Patch{}))
+}
@@ -0,0 +1,216 @@
+package reflection
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FieldSelector selects the struct fields that are validated
+// by ValidateStructFieldsWithOptions and checked
+// by ZeroValueExportedStructFieldNamesWithOptions,
+// see ValidateOptions.Fields.
+//
+// Include and Exclude contain field names or glob patterns
+// that are matched against the field names including the name prefix.
+// Patterns are split at dots into segments and every segment
+// is matched with path.Match, so "*" matches any part of a single field name.
+// A "**" segment matches any number of nested field names.
+// Index and map key segments of field names like "[2]" are ignored,
+// so "Addresses.Street" matches "Addresses[2].Street".
+//
+// Example:
+//
+//	reflection.FieldSelector{
+//	    Include: []string{"name", "address.*"},
+//	    Exclude: []string{"address.internal*"},
+//	    Filter: func(path string, field reflect.StructField) bool {
+//	        return field.Tag.Get("readonly") == ""
+//	    },
+//	}
+type FieldSelector struct {
+	// Include is an optional list of names or patterns of the fields to select.
+	// If empty, all fields are selected.
+	// The parents of selected nested fields are traversed
+	// but not selected themselves unless they are also included.
+	Include []string
+
+	// Exclude is an optional list of names or patterns of fields to skip.
+	// The nested fields of excluded fields are skipped too.
+	Exclude []string
+
+	// Filter is an optional predicate called with the name of every field
+	// including the name prefix and its reflect.StructField.
+	// Fields for which it returns false are skipped together with their nested fields.
+	Filter func(path string, field reflect.StructField) bool
+}
+
+// DefaultValidationGroup is the validation group
+// of fields without a `groups` struct tag, see ValidateOptions.Groups.
+const DefaultValidationGroup = "default"
+
+// FieldValidationGroups returns the validation groups of a struct field
+// from its comma separated `groups` struct tag
+// or DefaultValidationGroup if the field has no such tag.
+// Fields with an empty groups tag belong to no group
+// and are only validated if no groups are active.
+//
+// Example:
+//
+//	type User struct {
+//	    ID    string `groups:"update,admin"`
+//	    Email string
+//	}
+//
+//	field, _ := reflect.TypeFor[User]().FieldByName("ID")
+//	reflection.FieldValidationGroups(field) // [update admin]
+func FieldValidationGroups(field reflect.StructField) []string {
+	tag, ok := field.Tag.Lookup("groups")
+	if !ok {
+		return []string{DefaultValidationGroup}
+	}
+	var groups []string
+	for _, option := range ParseTagOptions(tag) {
+		if option.Key != "" {
+			groups = append(groups, option.Key)
+		}
+	}
+	return groups
+}
+
+// inValidationGroups returns if one of the fieldGroups
+// is in groups or if groups is empty.
+func inValidationGroups(fieldGroups, groups []string) bool {
+	if len(groups) == 0 {
+		return true
+	}
+	for _, group := range fieldGroups {
+		if slices.Contains(groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldPattern is a FieldSelector pattern split into segments.
+type fieldPattern []string
+
+func parseFieldPatterns(patterns []string) []fieldPattern {
+	parsed := make([]fieldPattern, len(patterns))
+	for i, pattern := range patterns {
+		parsed[i] = strings.Split(pattern, ".")
+		for _, segment := range parsed[i] {
+			if _, err := path.Match(segment, ""); err != nil || segment == "" {
+				panic(fmt.Errorf("invalid field pattern %q", pattern))
+			}
+		}
+	}
+	return parsed
+}
+
+// match returns if the pattern matches all name segments.
+func (p fieldPattern) match(name []string) bool {
+	if len(p) == 0 {
+		return len(name) == 0
+	}
+	if p[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if p[1:].match(name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(p[0], name[0])
+	return ok && p[1:].match(name[1:])
+}
+
+// matchNested returns if the pattern could match
+// a field nested in the field with the name segments.
+func (p fieldPattern) matchNested(name []string) bool {
+	if len(p) == 0 {
+		return false
+	}
+	if p[0] == "**" {
+		return true
+	}
+	if len(name) == 0 {
+		return true
+	}
+	ok, _ := path.Match(p[0], name[0])
+	return ok && p[1:].matchNested(name[1:])
+}
+
+// fieldSelector is a FieldSelector with parsed patterns.
+type fieldSelector struct {
+	include []fieldPattern
+	exclude []fieldPattern
+	filter  func(path string, field reflect.StructField) bool
+}
+
+func newFieldSelector(s *FieldSelector, namesToValidate []string) *fieldSelector {
+	if len(s.Include) == 0 && len(s.Exclude) == 0 && s.Filter == nil && len(namesToValidate) == 0 {
+		return nil
+	}
+	return &fieldSelector{
+		include: parseFieldPatterns(append(namesToValidate[:len(namesToValidate):len(namesToValidate)], s.Include...)),
+		exclude: parseFieldPatterns(s.Exclude),
+		filter:  s.Filter,
+	}
+}
+
+// selectField returns if the field with name is selected
+// and if it has to be traversed because it or its nested fields are selected.
+// A nil fieldSelector selects all fields.
+func (s *fieldSelector) selectField(name string, field reflect.StructField) (selected, traverse bool) {
+	if s == nil {
+		return true, true
+	}
+	if s.filter != nil && !s.filter(name, field) {
+		return false, false
+	}
+	if len(s.include) == 0 && len(s.exclude) == 0 {
+		return true, true
+	}
+	segments := fieldNameSegments(name)
+	for _, p := range s.exclude {
+		if p.match(segments) {
+			return false, false
+		}
+	}
+	if len(s.include) == 0 {
+		return true, true
+	}
+	for _, p := range s.include {
+		if p.match(segments) {
+			return true, true
+		}
+	}
+	for _, p := range s.include {
+		if p.matchNested(segments) {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// fieldNameSegments returns the field names of the path name
+// without index and map key segments.
+func fieldNameSegments(name string) []string {
+	p, err := ParseFieldPath(name)
+	if err != nil {
+		return strings.Split(name, ".")
+	}
+	segments := make([]string, 0, len(p))
+	for _, s := range p {
+		if !s.IsIndex && !s.IsKey {
+			segments = append(segments, s.Field)
+		}
+	}
+	return segments
+}
@@ -4,13 +4,19 @@ import (
 	"fmt"
 	"iter"
 	"reflect"
-	"strings"
+	"slices"
 )
 
 // FlatStructFieldCount returns the number of flattened struct fields.
 // Anonymous embedded fields are flattened, meaning their fields are counted
 // as top-level fields of the struct.
 //
+// Only embedded structs and pointers to structs are flattened.
+// Other embedded types like interfaces or named non-struct types,
+// struct types registered with RegisterNoFlattenType (like time.Time),
+// and embedded fields tagged with `reflection:"noflatten"`
+// are handled as a single field with the name of the type.
+//
 // Example:
 //
 //	type Base struct {
@@ -24,23 +30,15 @@ import (
 //	count := reflection.FlatStructFieldCount(reflect.TypeOf(Extended{}))
 //	fmt.Println(count) // 3 (ID, Name, Email)
 func FlatStructFieldCount(t reflect.Type) int {
-	t = DerefType(t)
-	count := 0
-	numField := t.NumField()
-	for i := range numField {
-		f := t.Field(i)
-		if f.Anonymous {
-			count += FlatStructFieldCount(f.Type)
-		} else {
-			count++
-		}
-	}
-	return count
+	return len(getFlatFields(DerefType(t)))
 }
 
 // FlatStructFieldNames returns the names of flattened struct fields.
 // Anonymous embedded fields are flattened, meaning their field names appear
 // at the top level alongside non-embedded fields.
+// Like with Go selectors, a field shadows fields with the same name
+// at deeper embedding depths and ambiguous fields with the same name
+// at the same depth are omitted, see AmbiguousFlatStructFields.
 //
 // Example:
 //
@@ -55,16 +53,10 @@ func FlatStructFieldCount(t reflect.Type) int {
 //	names := reflection.FlatStructFieldNames(reflect.TypeOf(Person{}))
 //	fmt.Println(names) // [Name Street City]
 func FlatStructFieldNames(t reflect.Type) (names []string) {
-	t = DerefType(t)
-	numField := t.NumField()
-	names = make([]string, 0, numField)
-	for i := range numField {
-		f := t.Field(i)
-		if f.Anonymous {
-			names = append(names, FlatStructFieldNames(f.Type)...)
-		} else {
-			names = append(names, f.Name)
-		}
+	fields := getFlatFields(DerefType(t))
+	names = make([]string, len(fields))
+	for i := range fields {
+		names[i] = fields[i].Field.Name
 	}
 	return names
 }
@@ -73,59 +65,115 @@ func FlatStructFieldNames(t reflect.Type) (names []string) {
 // meaning that the fields of anonoymous embedded fields are flattened
 // to the top level of the struct.
 // An empty string is returned for fields that don't have a matching tag.
+//
+// Like with encoding/json, an anonymous embedded struct
+// with a name in its tagKey tag is not flattened
+// but returned as a single field with its tag value.
 func FlatStructFieldTags(t reflect.Type, tagKey string) (tagValues []string) {
-	t = DerefType(t)
-	numField := t.NumField()
-	tagValues = make([]string, 0, numField)
-	for i := range numField {
-		f := t.Field(i)
-		if f.Anonymous {
-			tagValues = append(tagValues, FlatStructFieldNames(f.Type)...)
-		} else {
-			tagValues = append(tagValues, f.Tag.Get(tagKey))
-		}
+	fields := getStructTypeInfo(DerefType(t)).tagInfo(tagNameResolver(tagKey)).flatFields
+	tagValues = make([]string, len(fields))
+	for i := range fields {
+		tagValues[i] = fields[i].Field.Tag.Get(tagKey)
 	}
 	return tagValues
 }
 
-// FlatStructFieldTagsOrNames returns the tag values for tagKey or the names of the field
-// if no tag with tagKey is defined at a struct field.
+// FlatStructFieldTagsOrNames returns the names from the tags with nameTag or the names of the field
+// if no tag with a name is defined at a struct field.
+// The argument nameTag can be a struct tag key string or a *NameResolver.
 // Fields are flattened,
 // meaning that the fields of anonoymous embedded fields are flattened
 // to the top level of the struct.
-func FlatStructFieldTagsOrNames(t reflect.Type, tagKey string) (tagsOrNames []string) {
-	t = DerefType(t)
-	numField := t.NumField()
-	tagsOrNames = make([]string, 0, numField)
-	for i := range numField {
-		f := t.Field(i)
-		if f.Anonymous {
-			tagsOrNames = append(tagsOrNames, FlatStructFieldNames(f.Type)...)
-		} else {
-			tagOrName := f.Tag.Get(tagKey)
-			if tagOrName == "" {
-				tagOrName = f.Name
+//
+// Like with encoding/json, an anonymous embedded struct
+// with a name in its nameTag tag is not flattened
+// but returned as a single field with its tag name.
+func FlatStructFieldTagsOrNames[N NameSource](t reflect.Type, nameTag N) (tagsOrNames []string) {
+	info := getStructTypeInfo(DerefType(t)).tagInfo(nameResolverOf(nameTag))
+	tagsOrNames = make([]string, len(info.flatTags))
+	for i := range info.flatTags {
+		tagsOrNames[i] = info.flatTags[i].FieldName
+	}
+	return tagsOrNames
+}
+
+// NilEmbeddedMode defines how the fields of a nil pointer
+// to an anonymous embedded struct are handled when flattening struct values.
+type NilEmbeddedMode int
+
+const (
+	// NilEmbeddedSkip skips the fields of nil embedded struct pointers.
+	NilEmbeddedSkip NilEmbeddedMode = iota
+
+	// NilEmbeddedInvalid returns an invalid reflect.Value
+	// for the fields of nil embedded struct pointers.
+	NilEmbeddedInvalid
+
+	// NilEmbeddedAlloc allocates a new struct for nil embedded struct pointers
+	// so that the returned field values can be set.
+	// Requires an addressable struct value, like when passing a pointer to a struct.
+	NilEmbeddedAlloc
+)
+
+// String implements the fmt.Stringer interface.
+func (mode NilEmbeddedMode) String() string {
+	switch mode {
+	case NilEmbeddedSkip:
+		return "NilEmbeddedSkip"
+	case NilEmbeddedInvalid:
+		return "NilEmbeddedInvalid"
+	case NilEmbeddedAlloc:
+		return "NilEmbeddedAlloc"
+	}
+	return fmt.Sprintf("NilEmbeddedMode(%d)", int(mode))
+}
+
+// flatFieldValue returns the field of the struct value v
+// at the flattened field index path.
+// If a nil pointer to an embedded struct is found along the path,
+// then an invalid reflect.Value and false is returned
+// or the embedded struct is allocated depending on mode.
+func flatFieldValue(v reflect.Value, index []int, mode NilEmbeddedMode) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if mode != NilEmbeddedAlloc {
+					return reflect.Value{}, false
+				}
+				if !v.CanSet() {
+					panic(fmt.Errorf("can't allocate nil embedded %s of non-addressable or unexported field", v.Type()))
+				}
+				v.Set(reflect.New(v.Type().Elem()))
 			}
-			tagsOrNames = append(tagsOrNames, tagOrName)
+			v = v.Elem()
 		}
+		v = v.Field(x)
 	}
-	return tagsOrNames
+	return v, true
 }
 
 // FlatStructFieldValues returns the values of flattened struct fields,
 // meaning that the fields of anonoymous embedded fields are flattened
 // to the top level of the struct.
+// Invalid reflect.Value are returned for the fields
+// of nil pointers to embedded structs so that the result
+// corresponds with the result of FlatStructFieldNames.
 func FlatStructFieldValues(v reflect.Value) (values []reflect.Value) {
+	return FlatStructFieldValuesMode(v, NilEmbeddedInvalid)
+}
+
+// FlatStructFieldValuesMode returns the values of flattened struct fields,
+// meaning that the fields of anonoymous embedded fields are flattened
+// to the top level of the struct.
+// The argument nilEmbedded defines how the fields of nil pointers
+// to embedded structs are handled.
+func FlatStructFieldValuesMode(v reflect.Value, nilEmbedded NilEmbeddedMode) (values []reflect.Value) {
 	v = DerefValue(v)
-	t := v.Type()
-	numField := t.NumField()
-	values = make([]reflect.Value, 0, numField)
-	for i := range numField {
-		ft := t.Field(i)
-		fv := v.Field(i)
-		if ft.Anonymous {
-			values = append(values, FlatStructFieldValues(fv)...)
-		} else {
+	fields := getFlatFields(v.Type())
+	values = make([]reflect.Value, 0, len(fields))
+	for i := range fields {
+		fv, ok := flatFieldValue(v, fields[i].Index, nilEmbedded)
+		if ok || nilEmbedded == NilEmbeddedInvalid {
 			values = append(values, fv)
 		}
 	}
@@ -152,26 +200,63 @@ func FlatStructFieldValues(v reflect.Value) (values []reflect.Value) {
 type StructFieldValue struct {
 	Field reflect.StructField // Type information about the field
 	Value reflect.Value       // Runtime value of the field
+	Index []int               // Index path of the field from the outermost struct for reflect.Value.FieldByIndex
+	Depth int                 // Embedding depth of the field, 0 for direct fields of the struct
 }
 
 // FlatExportedStructFields returns a slice of StructFieldValue of flattened struct fields,
 // meaning that the fields of anonoymous embedded fields are flattened
 // to the top level of the struct.
+// The fields of nil pointers to embedded structs are skipped.
 // The argument val can be a struct, a pointer to a struct, or a reflect.Value.
 func FlatExportedStructFields(val any) []StructFieldValue {
+	return FlatExportedStructFieldsMode(val, NilEmbeddedSkip)
+}
+
+// FlatExportedStructFieldsMode returns a slice of StructFieldValue of flattened struct fields,
+// meaning that the fields of anonoymous embedded fields are flattened
+// to the top level of the struct.
+// The argument nilEmbedded defines how the fields of nil pointers
+// to embedded structs are handled.
+// The argument val can be a struct, a pointer to a struct, or a reflect.Value.
+//
+// Example:
+//
+//	type Base struct {
+//	    ID int
+//	}
+//	type Extended struct {
+//	    *Base
+//	    Name string
+//	}
+//	var e Extended
+//	for _, field := range reflection.FlatExportedStructFieldsMode(&e, reflection.NilEmbeddedAlloc) {
+//	    if field.Field.Name == "ID" {
+//	        field.Value.SetInt(1)
+//	    }
+//	}
+//	fmt.Println(e.ID) // 1
+func FlatExportedStructFieldsMode(val any, nilEmbedded NilEmbeddedMode) []StructFieldValue {
 	v, t := DerefValueAndType(val)
 	if t.Kind() != reflect.Struct {
-		panic(fmt.Errorf("FlatExportedStructFields expects struct, pointer to or reflect.Value of a struct argument, but got: %T", val))
+		panic(fmt.Errorf("FlatExportedStructFieldsMode expects struct, pointer to or reflect.Value of a struct argument, but got: %T", val))
 	}
-	numField := t.NumField()
-	fields := make([]StructFieldValue, 0, numField)
-	for i := range numField {
-		fieldType := t.Field(i)
-		fieldValue := v.Field(i)
-		if fieldType.Anonymous {
-			fields = append(fields, FlatExportedStructFields(fieldValue)...)
-		} else if fieldType.IsExported() {
-			fields = append(fields, StructFieldValue{fieldType, fieldValue})
+	flatFields := getFlatFields(t)
+	fields := make([]StructFieldValue, 0, len(flatFields))
+	indices := newIndexBuffer(flatFields)
+	for i := range flatFields {
+		if !flatFields[i].Field.IsExported() {
+			continue
+		}
+		fv, ok := flatFieldValue(v, flatFields[i].Index, nilEmbedded)
+		if ok || nilEmbedded == NilEmbeddedInvalid {
+			field, index := indices.clone(&flatFields[i])
+			fields = append(fields, StructFieldValue{
+				Field: field,
+				Value: fv,
+				Index: index,
+				Depth: flatFields[i].Depth(),
+			})
 		}
 	}
 	return fields
@@ -180,19 +265,22 @@ func FlatExportedStructFields(val any) []StructFieldValue {
 // EnumFlatExportedStructFields returns reflect.StructField and reflect.Value of flattened struct fields,
 // meaning that the fields of anonoymous embedded fields are flattened
 // to the top level of the struct.
+// The fields of nil pointers to embedded structs are skipped.
+// The Index slice of the reflect.StructField passed to callback
+// is shared with the cached type information and must not be modified.
 // The argument val can be a struct, a pointer to a struct, or a reflect.Value.
 func EnumFlatExportedStructFields(val any, callback func(reflect.StructField, reflect.Value)) {
 	v, t := DerefValueAndType(val)
 	if t.Kind() != reflect.Struct {
 		panic(fmt.Errorf("EnumFlatExportedStructFields expects struct, pointer to or reflect.Value of a struct argument, but got: %T", val))
 	}
-	for i := range t.NumField() {
-		fieldType := t.Field(i)
-		fieldValue := v.Field(i)
-		if fieldType.Anonymous {
-			EnumFlatExportedStructFields(fieldValue, callback)
-		} else if fieldType.IsExported() {
-			callback(fieldType, fieldValue)
+	flatFields := getFlatFields(t)
+	for i := range flatFields {
+		if !flatFields[i].Field.IsExported() {
+			continue
+		}
+		if fv, ok := flatFieldValue(v, flatFields[i].Index, NilEmbeddedSkip); ok {
+			callback(flatFields[i].Field, fv)
 		}
 	}
 }
@@ -202,6 +290,9 @@ func EnumFlatExportedStructFields(val any, callback func(reflect.StructField, re
 //
 // This is the most memory-efficient way to iterate over struct fields,
 // as it doesn't allocate a slice. Requires Go 1.23+.
+// The fields of nil pointers to embedded structs are skipped.
+// The Index slice of the yielded reflect.StructField
+// is shared with the cached type information and must not be modified.
 //
 // The argument s can be a struct, a pointer to a struct, or a reflect.Value.
 //
@@ -219,45 +310,46 @@ func EnumFlatExportedStructFields(val any, callback func(reflect.StructField, re
 //	// Name = Bob
 //	// Email = bob@example.com
 func FlatExportedStructFieldsIter(s any) iter.Seq2[reflect.StructField, reflect.Value] {
+	return FlatExportedStructFieldsIterMode(s, NilEmbeddedSkip)
+}
+
+// FlatExportedStructFieldsIterMode returns an iterator over flattened exported struct fields.
+// Anonymous embedded fields are flattened to the top level.
+// The argument nilEmbedded defines how the fields of nil pointers
+// to embedded structs are handled.
+// With NilEmbeddedAlloc the embedded structs are allocated
+// only when the iteration reaches their fields.
+//
+// The argument s can be a struct, a pointer to a struct, or a reflect.Value.
+func FlatExportedStructFieldsIterMode(s any, nilEmbedded NilEmbeddedMode) iter.Seq2[reflect.StructField, reflect.Value] {
 	v, t := DerefValueAndType(s)
 	if t.Kind() != reflect.Struct {
-		panic(fmt.Errorf("FlatExportedStructFieldsIter expects struct or pointer to or reflect.Value of a struct argument, but got: %T", s))
+		panic(fmt.Errorf("FlatExportedStructFieldsIterMode expects struct or pointer to or reflect.Value of a struct argument, but got: %T", s))
 	}
+	flatFields := getFlatFields(t)
 	return func(yield func(reflect.StructField, reflect.Value) bool) {
-		for i := range t.NumField() {
-			field, val := t.Field(i), v.Field(i)
-			switch {
-			case field.Anonymous:
-				for fieldA, valA := range FlatExportedStructFieldsIter(val) {
-					if !yield(fieldA, valA) {
-						return
-					}
-				}
-			case field.IsExported():
-				if !yield(field, val) {
-					return
-				}
+		for i := range flatFields {
+			if !flatFields[i].Field.IsExported() {
+				continue
+			}
+			fv, ok := flatFieldValue(v, flatFields[i].Index, nilEmbedded)
+			if !ok && nilEmbedded != NilEmbeddedInvalid {
+				continue
+			}
+			if !yield(flatFields[i].Field, fv) {
+				return
 			}
 		}
 	}
 }
 
-// nameTag can be empty
-func exportedFieldName(field reflect.StructField, nameTag string) (name string, valid bool) {
-	if !field.IsExported() {
-		return "", false
-	}
-	name, ok := field.Tag.Lookup(nameTag)
-	if !ok {
-		return field.Name, true
-	}
-	if pos := strings.IndexRune(name, ','); pos != -1 {
-		name = name[:pos]
-	}
-	if name == "-" {
+// exportedFieldName returns the resolved name of an exported field
+// or false if the field is not exported or ignored by its tag.
+func exportedFieldName(field reflect.StructField, tag fieldTag) (name string, valid bool) {
+	if !field.IsExported() || tag.IsIgnored() {
 		return "", false
 	}
-	return name, true
+	return tag.FieldName, true
 }
 
 // StructFieldValueName combines field type information, runtime value, and a custom name.
@@ -283,27 +375,38 @@ type StructFieldValueName struct {
 	Field reflect.StructField // Type information about the field
 	Value reflect.Value       // Runtime value of the field
 	Name  string              // Custom name from struct tag or field name
+	Index []int               // Index path of the field from the outermost struct for reflect.Value.FieldByIndex
+	Depth int                 // Embedding depth of the field, 0 for direct fields of the struct
 }
 
 // FlatExportedStructFieldValueNames returns a slice of StructFieldValueName of flattened struct fields,
 // meaning that the fields of anonoymous embedded fields are flattened
 // to the top level of the struct.
+// The fields of nil pointers to embedded structs are skipped.
+// An anonymous embedded struct with a name from nameTag
+// is not flattened but returned as a single field with that name.
+// The argument nameTag can be a struct tag key string or a *NameResolver.
 // The argument val can be a struct, a pointer to a struct, or a reflect.Value.
-func FlatExportedStructFieldValueNames(val any, nameTag string) []StructFieldValueName {
+func FlatExportedStructFieldValueNames[N NameSource](val any, nameTag N) []StructFieldValueName {
 	v, t := DerefValueAndType(val)
 	if t.Kind() != reflect.Struct {
 		panic(fmt.Errorf("FlatExportedStructFieldValueNames expects struct, pointer to or reflect.Value of a struct argument, but got: %T", val))
 	}
-	numField := t.NumField()
-	fields := make([]StructFieldValueName, 0, numField)
-	for i := range numField {
-		fieldType := t.Field(i)
-		fieldValue := v.Field(i)
-		if fieldType.Anonymous {
-			fields = append(fields, FlatExportedStructFieldValueNames(fieldValue, nameTag)...)
-		} else {
-			if name, valid := exportedFieldName(fieldType, nameTag); valid {
-				fields = append(fields, StructFieldValueName{fieldType, fieldValue, name})
+	info := getStructTypeInfo(t).tagInfo(nameResolverOf(nameTag))
+	fields := make([]StructFieldValueName, 0, len(info.flatFields))
+	indices := newIndexBuffer(info.flatFields)
+	for i := range info.flatFields {
+		field := &info.flatFields[i]
+		if name, valid := exportedFieldName(field.Field, info.flatTags[i]); valid {
+			if fv, ok := flatFieldValue(v, field.Index, NilEmbeddedSkip); ok {
+				structField, index := indices.clone(field)
+				fields = append(fields, StructFieldValueName{
+					Field: structField,
+					Value: fv,
+					Name:  name,
+					Index: index,
+					Depth: field.Depth(),
+				})
 			}
 		}
 	}
@@ -313,30 +416,39 @@ func FlatExportedStructFieldValueNames(val any, nameTag string) []StructFieldVal
 // FlatExportedStructFieldValueNameMap returns a slice of StructFieldValueName of flattened struct fields,
 // meaning that the fields of anonoymous embedded fields are flattened
 // to the top level of the struct.
+// The fields of nil pointers to embedded structs are skipped.
+// An anonymous embedded struct with a name from nameTag
+// is not flattened but returned as a single field with that name.
+// The argument nameTag can be a struct tag key string or a *NameResolver.
 // The argument val can be a struct, a pointer to a struct, or a reflect.Value.
-func FlatExportedStructFieldValueNameMap(val any, nameTag string) map[string]StructFieldValueName {
-	fields := make(map[string]StructFieldValueName)
-	flatExportedStructFieldValueNameMap(val, nameTag, fields)
-	return fields
-}
-
-func flatExportedStructFieldValueNameMap(val any, nameTag string, fields map[string]StructFieldValueName) {
+//
+// Names are resolved like with encoding/json: the field at the shallowest
+// embedding depth wins, at the same depth a name from nameTag wins,
+// and otherwise ambiguous names are omitted, see AmbiguousFlatStructFields.
+func FlatExportedStructFieldValueNameMap[N NameSource](val any, nameTag N) map[string]StructFieldValueName {
 	v, t := DerefValueAndType(val)
 	if t.Kind() != reflect.Struct {
 		panic(fmt.Errorf("FlatExportedStructFieldValueNameMap expects struct, pointer to or reflect.Value of a struct argument, but got: %T", val))
 	}
-	numField := t.NumField()
-	for i := range numField {
-		fieldType := t.Field(i)
-		fieldValue := v.Field(i)
-		if fieldType.Anonymous {
-			flatExportedStructFieldValueNameMap(fieldValue, nameTag, fields)
-		} else {
-			if name, valid := exportedFieldName(fieldType, nameTag); valid {
-				fields[name] = StructFieldValueName{fieldType, fieldValue, name}
+	info := getStructTypeInfo(t).tagInfo(nameResolverOf(nameTag))
+	fields := make(map[string]StructFieldValueName, len(info.flatFields))
+	indices := newIndexBuffer(info.flatFields)
+	for i := range info.flatFields {
+		field := &info.flatFields[i]
+		if name, valid := exportedFieldName(field.Field, info.flatTags[i]); valid {
+			if fv, ok := flatFieldValue(v, field.Index, NilEmbeddedSkip); ok {
+				structField, index := indices.clone(field)
+				fields[name] = StructFieldValueName{
+					Field: structField,
+					Value: fv,
+					Name:  name,
+					Index: index,
+					Depth: field.Depth(),
+				}
 			}
 		}
 	}
+	return fields
 }
 
 // NamedStructField combines field type information with a custom name.
@@ -359,28 +471,99 @@ func flatExportedStructFieldValueNameMap(val any, nameTag string, fields map[str
 type NamedStructField struct {
 	Field reflect.StructField // Type information about the field
 	Name  string              // Custom name from struct tag or field name
+	Index []int               // Index path of the field from the outermost struct for reflect.Value.FieldByIndex
+	Depth int                 // Embedding depth of the field, 0 for direct fields of the struct
+}
+
+// Value returns the value of the field in strct
+// by following the index path of the field.
+// This makes it possible to use the result of FlatExportedNamedStructFields
+// computed once for a type with any value of that type.
+// An error is returned if a nil pointer to an embedded struct
+// is on the index path.
+// The argument strct can be a struct, a pointer to a struct, or a reflect.Value.
+//
+// Example:
+//
+//	fields := reflection.FlatExportedNamedStructFields(reflect.TypeOf(Config{}), "json")
+//	value, err := fields[0].Value(&Config{Host: "localhost"})
+//	fmt.Println(value, err) // localhost <nil>
+func (f *NamedStructField) Value(strct any) (reflect.Value, error) {
+	return DerefValue(strct).FieldByIndexErr(f.Index)
 }
 
 // FlatExportedNamedStructFields returns a slice of NamedStructField of flattened struct fields,
 // meaning that the fields of anonoymous embedded fields are flattened
 // to the top level of the struct.
+// An anonymous embedded struct with a name from nameTag
+// is not flattened but returned as a single field with that name.
+// The argument nameTag can be a struct tag key string or a *NameResolver.
 // The argument t can be a struct, a pointer to a struct, or a reflect.Value.
-func FlatExportedNamedStructFields(t reflect.Type, nameTag string) []NamedStructField {
+func FlatExportedNamedStructFields[N NameSource](t reflect.Type, nameTag N) []NamedStructField {
 	t = DerefType(t)
 	if t.Kind() != reflect.Struct {
 		panic(fmt.Errorf("FlatExportedNamedStructFields expects struct, pointer to or reflect.Value of a struct argument, but got: %s", t))
 	}
-	numField := t.NumField()
-	fields := make([]NamedStructField, 0, numField)
-	for i := range numField {
-		field := t.Field(i)
-		if field.Anonymous {
-			fields = append(fields, FlatExportedNamedStructFields(field.Type, nameTag)...)
-		} else {
-			if name, valid := exportedFieldName(field, nameTag); valid {
-				fields = append(fields, NamedStructField{field, name})
-			}
+	info := getStructTypeInfo(t).tagInfo(nameResolverOf(nameTag))
+	fields := make([]NamedStructField, 0, len(info.flatFields))
+	indices := newIndexBuffer(info.flatFields)
+	for i := range info.flatFields {
+		if name, valid := exportedFieldName(info.flatFields[i].Field, info.flatTags[i]); valid {
+			field, index := indices.clone(&info.flatFields[i])
+			fields = append(fields, NamedStructField{
+				Field: field,
+				Name:  name,
+				Index: index,
+				Depth: info.flatFields[i].Depth(),
+			})
 		}
 	}
 	return fields
 }
+
+// AmbiguousStructField describes fields with the same name
+// at the same embedding depth that are dropped from the flattened fields,
+// because selecting one of them would be ambiguous.
+type AmbiguousStructField struct {
+	Name   string                // The ambiguous name of the fields
+	Depth  int                   // Embedding depth of the fields, 0 for direct fields of the struct
+	Fields []reflect.StructField // The fields sharing the ambiguous name
+}
+
+// AmbiguousFlatStructFields returns the fields that are dropped
+// when flattening the struct type t because of ambiguous names.
+//
+// Flattening follows the selector rules of Go for embedded fields:
+// A field at a shallower embedding depth shadows fields with the same name
+// at deeper depths, and fields with the same name at the same depth
+// are ambiguous and dropped.
+// Like with encoding/json, a field that has its name from the nameTag
+// wins over fields at the same depth that don't have a name from a tag.
+// The argument nameTag can be a struct tag key string or a *NameResolver,
+// if nameTag is an empty string, then the Go field names are used.
+//
+// Example:
+//
+//	type A struct{ ID int }
+//	type B struct{ ID int }
+//	type C struct {
+//	    A
+//	    B
+//	}
+//	ambiguous := reflection.AmbiguousFlatStructFields(reflect.TypeOf(C{}), "")
+//	fmt.Println(ambiguous[0].Name) // ID
+func AmbiguousFlatStructFields[N NameSource](t reflect.Type, nameTag N) []AmbiguousStructField {
+	t = DerefType(t)
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("AmbiguousFlatStructFields expects struct or pointer to struct type, but got: %s", t))
+	}
+	ambiguous := slices.Clone(getStructTypeInfo(t).tagInfo(nameResolverOf(nameTag)).ambiguous)
+	for i := range ambiguous {
+		// Don't share the fields and their index slices with the cache
+		ambiguous[i].Fields = slices.Clone(ambiguous[i].Fields)
+		for j := range ambiguous[i].Fields {
+			ambiguous[i].Fields[j].Index = slices.Clone(ambiguous[i].Fields[j].Index)
+		}
+	}
+	return ambiguous
+}
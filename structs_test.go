@@ -0,0 +1,369 @@
+package reflection
+
+import (
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type benchBase struct {
+	ID      int64  `db:"id" json:"id"`
+	Created string `db:"created" json:"created,omitempty"`
+	Updated string `db:"updated" json:"updated,omitempty"`
+}
+
+type benchAddress struct {
+	Street string `db:"street" json:"street"`
+	City   string `db:"city" json:"city"`
+	Zip    string `db:"zip" json:"zip"`
+}
+
+type benchUser struct {
+	benchBase
+	*benchAddress
+	Name     string   `db:"name" json:"name"`
+	Email    string   `db:"email" json:"email"`
+	Age      int      `db:"age" json:"age,omitempty"`
+	Tags     []string `db:"-" json:"tags"`
+	internal bool
+}
+
+func newBenchUser() *benchUser {
+	return &benchUser{
+		benchBase:    benchBase{ID: 1, Created: "yesterday"},
+		benchAddress: &benchAddress{Street: "Main St", City: "Springfield"},
+		Name:         "Alice",
+		Email:        "alice@example.com",
+	}
+}
+
+// uncachedFlatExportedStructFieldValueNames is the implementation
+// of FlatExportedStructFieldValueNames before the introduction
+// of the type info cache, used as benchmark baseline.
+func uncachedFlatExportedStructFieldValueNames(val any, nameTag string) []StructFieldValueName {
+	v, t := DerefValueAndType(val)
+	numField := t.NumField()
+	fields := make([]StructFieldValueName, 0, numField)
+	for i := range numField {
+		fieldType := t.Field(i)
+		fieldValue := v.Field(i)
+		if fieldType.Anonymous {
+			fields = append(fields, uncachedFlatExportedStructFieldValueNames(fieldValue, nameTag)...)
+		} else if fieldType.IsExported() {
+			value, found := fieldType.Tag.Lookup(nameTag)
+			name := fieldType.Name
+			if found {
+				name = value
+				for i := range value {
+					if value[i] == ',' {
+						name = value[:i]
+						break
+					}
+				}
+			}
+			if name != "-" {
+				fields = append(fields, StructFieldValueName{Field: fieldType, Value: fieldValue, Name: name})
+			}
+		}
+	}
+	return fields
+}
+
+// uncachedFlatStructFieldNames is the implementation
+// of FlatStructFieldNames before the introduction
+// of the type info cache, used as benchmark baseline.
+func uncachedFlatStructFieldNames(t reflect.Type) (names []string) {
+	t = DerefType(t)
+	numField := t.NumField()
+	names = make([]string, 0, numField)
+	for i := range numField {
+		f := t.Field(i)
+		if f.Anonymous {
+			names = append(names, uncachedFlatStructFieldNames(f.Type)...)
+		} else {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+func TestFlatExportedStructFieldValueNames(t *testing.T) {
+	user := newBenchUser()
+	expected := uncachedFlatExportedStructFieldValueNames(user, "db")
+	actual := FlatExportedStructFieldValueNames(user, "db")
+	assert.Equal(t, len(expected), len(actual))
+	for i := range expected {
+		assert.Equal(t, expected[i].Name, actual[i].Name)
+		assert.Equal(t, expected[i].Field.Name, actual[i].Field.Name)
+		assert.Equal(t, expected[i].Value.Interface(), actual[i].Value.Interface())
+	}
+	assert.Equal(t, uncachedFlatStructFieldNames(reflect.TypeOf(user)), FlatStructFieldNames(reflect.TypeOf(user)))
+}
+
+func BenchmarkFlatExportedStructFieldValueNames(b *testing.B) {
+	user := newBenchUser()
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			uncachedFlatExportedStructFieldValueNames(user, "db")
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			FlatExportedStructFieldValueNames(user, "db")
+		}
+	})
+}
+
+func BenchmarkFlatStructFieldNames(b *testing.B) {
+	t := reflect.TypeOf(benchUser{})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			uncachedFlatStructFieldNames(t)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			FlatStructFieldNames(t)
+		}
+	})
+}
+
+func BenchmarkValidateStructFields(b *testing.B) {
+	user := newBenchUser()
+	validateFunc := func(any) error { return nil }
+	b.ReportAllocs()
+	for range b.N {
+		ValidateStructFields(validateFunc, user, "", "json")
+	}
+}
+
+func TestFlatStructFieldShadowing(t *testing.T) {
+	type Inner struct {
+		ID    int
+		Name  string
+		Inner string
+	}
+	type Other struct {
+		Name  string
+		Title string `json:"Name"`
+	}
+	type Outer struct {
+		Inner
+		Other
+		ID int
+	}
+
+	// Outer.ID shadows Inner.ID, Inner.Name and Other.Name are ambiguous
+	assert.Equal(t, []string{"Inner", "Title", "ID"}, FlatStructFieldNames(reflect.TypeOf(Outer{})))
+	ambiguous := AmbiguousFlatStructFields(reflect.TypeOf(Outer{}), "")
+	if assert.Len(t, ambiguous, 1) {
+		assert.Equal(t, "Name", ambiguous[0].Name)
+		assert.Equal(t, 1, ambiguous[0].Depth)
+		assert.Len(t, ambiguous[0].Fields, 2)
+	}
+
+	// The tagged Other.Title wins over the untagged
+	// Other.Name and Inner.Name at the same depth
+	fields := FlatExportedStructFieldValueNameMap(&Outer{Other: Other{Title: "Title"}}, "json")
+	assert.Equal(t, "Title", fields["Name"].Value.Interface())
+	assert.Len(t, fields, 3)
+	assert.Empty(t, AmbiguousFlatStructFields(reflect.TypeOf(Outer{}), "json"))
+
+	// The same type embedded twice at the same depth
+	type A struct{ Inner }
+	type B struct{ Inner }
+	type Twice struct {
+		A
+		B
+		Name string
+	}
+	assert.Equal(t, []string{"Name"}, FlatStructFieldNames(reflect.TypeOf(Twice{})))
+	assert.Len(t, AmbiguousFlatStructFields(reflect.TypeOf(Twice{}), ""), 2)
+
+	// Recursive embedding
+	type Node struct {
+		*Node
+		Value int
+	}
+	assert.Equal(t, []string{"Value"}, FlatStructFieldNames(reflect.TypeOf(Node{})))
+
+	// Unexported fields don't shadow promoted tagged fields like with encoding/json
+	type TaggedInner struct {
+		Name string `json:"name"`
+	}
+	type Unexported struct {
+		TaggedInner
+		name string
+	}
+	u := Unexported{TaggedInner: TaggedInner{Name: "n"}, name: "x"}
+	named := FlatExportedStructFieldValueNameMap(u, "json")
+	if assert.Len(t, named, 1) {
+		assert.Equal(t, "n", named["name"].Value.Interface())
+	}
+	assert.Equal(t, []string{"name"}, ZeroValueExportedStructFieldNames(Unexported{}, "", "json"))
+
+	// The returned ambiguous fields don't share memory with the cache
+	ambiguous = AmbiguousFlatStructFields(reflect.TypeOf(Outer{}), "")
+	ambiguous[0].Fields[0].Name = "Modified"
+	ambiguous[0].Fields[0].Index[0] = 99
+	ambiguous = AmbiguousFlatStructFields(reflect.TypeOf(Outer{}), "")
+	assert.Equal(t, "Name", ambiguous[0].Fields[0].Name)
+	assert.NotEqual(t, 99, ambiguous[0].Fields[0].Index[0])
+}
+
+func TestFlatStructFieldsNilEmbedded(t *testing.T) {
+	type Base struct {
+		ID int
+	}
+	type Extended struct {
+		*Base
+		Name string
+	}
+
+	e := Extended{Name: "Name"}
+	assert.Len(t, FlatStructFieldValues(reflect.ValueOf(e)), 2)
+	assert.False(t, FlatStructFieldValues(reflect.ValueOf(e))[0].IsValid())
+	assert.Len(t, FlatExportedStructFields(e), 1)
+	assert.Len(t, FlatExportedStructFieldsMode(e, NilEmbeddedInvalid), 2)
+	assert.Len(t, FlatExportedStructFieldValueNames(e, ""), 1)
+	count := 0
+	for range FlatExportedStructFieldsIter(e) {
+		count++
+	}
+	assert.Equal(t, 1, count)
+
+	for field, value := range FlatExportedStructFieldsIterMode(&e, NilEmbeddedAlloc) {
+		if field.Name == "ID" {
+			value.SetInt(7)
+		}
+	}
+	if assert.NotNil(t, e.Base) {
+		assert.Equal(t, 7, e.ID)
+	}
+	assert.Len(t, FlatExportedStructFields(e), 2)
+
+	assert.Panics(t, func() { FlatExportedStructFieldsMode(Extended{}, NilEmbeddedAlloc) })
+}
+
+type testID int
+
+type testTimestamp struct {
+	Created time.Time
+	Updated time.Time
+}
+
+func TestFlatStructFieldsNoFlatten(t *testing.T) {
+	type Base struct {
+		Name string
+	}
+	type Embedding struct {
+		testID
+		io.Reader
+		time.Time
+		Base `reflection:"noflatten"`
+		testTimestamp
+		Value int
+	}
+	typ := reflect.TypeOf(Embedding{})
+
+	assert.Equal(t, []string{"testID", "Reader", "Time", "Base", "Created", "Updated", "Value"}, FlatStructFieldNames(typ))
+	assert.Equal(t, 7, FlatStructFieldCount(typ))
+
+	RegisterNoFlattenType(reflect.TypeFor[testTimestamp]())
+	assert.Equal(t, []string{"testID", "Reader", "Time", "Base", "testTimestamp", "Value"}, FlatStructFieldNames(typ))
+
+	e := Embedding{testID: 1, Base: Base{Name: "Name"}}
+	fields := FlatExportedStructFieldValueNameMap(e, "")
+	assert.Equal(t, Base{Name: "Name"}, fields["Base"].Value.Interface())
+	assert.Contains(t, fields, "Reader")
+	assert.NotContains(t, fields, "testID")
+}
+
+func TestFlatStructFieldsTaggedEmbedded(t *testing.T) {
+	type Meta struct {
+		Version int    `json:"version"`
+		Author  string `json:"author,omitempty"`
+	}
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type Hidden struct {
+		Secret string `json:"secret"`
+	}
+	type Document struct {
+		Base
+		Meta   `json:"meta"`
+		Hidden `json:"-"`
+		Title  string `json:"title"`
+	}
+	typ := reflect.TypeOf(Document{})
+
+	assert.Equal(t, []string{"id", "meta", "-", "title"}, FlatStructFieldTags(typ, "json"))
+	assert.Equal(t, []string{"id", "meta", "-", "title"}, FlatStructFieldTagsOrNames(typ, "json"))
+	assert.Equal(t, []string{"ID", "Version", "Author", "Secret", "Title"}, FlatStructFieldTagsOrNames(typ, "xml"))
+	assert.Equal(t, []string{"", "", "", "", ""}, FlatStructFieldTags(typ, "xml"))
+
+	fields := FlatExportedNamedStructFields(typ, "json")
+	if assert.Len(t, fields, 3) {
+		assert.Equal(t, "meta", fields[1].Name)
+		assert.Equal(t, reflect.TypeFor[Meta](), fields[1].Field.Type)
+	}
+}
+
+func TestFlatStructFieldsIndex(t *testing.T) {
+	type Inner struct {
+		Value string `json:"value"`
+	}
+	type Middle struct {
+		*Inner
+		Count int `json:"count"`
+	}
+	type Outer struct {
+		Name string `json:"name"`
+		Middle
+	}
+
+	fields := FlatExportedNamedStructFields(reflect.TypeOf(Outer{}), "json")
+	if !assert.Len(t, fields, 3) {
+		return
+	}
+	assert.Equal(t, []int{0}, fields[0].Index)
+	assert.Equal(t, 0, fields[0].Depth)
+	assert.Equal(t, []int{1, 0, 0}, fields[1].Index)
+	assert.Equal(t, 2, fields[1].Depth)
+	assert.Equal(t, []int{1, 1}, fields[2].Index)
+	assert.Equal(t, 1, fields[2].Depth)
+
+	o := &Outer{Name: "Name", Middle: Middle{Inner: &Inner{Value: "Value"}, Count: 3}}
+	for _, field := range fields {
+		value, err := field.Value(o)
+		assert.NoError(t, err)
+		assert.Equal(t, reflect.ValueOf(o).Elem().FieldByIndex(field.Index).Interface(), value.Interface())
+	}
+	_, err := fields[1].Value(Outer{})
+	assert.Error(t, err, "nil embedded pointer")
+
+	values := FlatExportedStructFieldValueNames(o, "json")
+	if assert.Len(t, values, 3) {
+		assert.Equal(t, fields[1].Index, values[1].Index)
+		assert.Equal(t, fields[1].Depth, values[1].Depth)
+	}
+
+	// Modifying the returned index paths doesn't change the cached paths
+	fields[1].Index[0] = 42
+	fields[1].Field.Index[0] = 42
+	values[1].Index[0] = 42
+	structFields := FlatExportedStructFields(o)
+	structFields[1].Index[0] = 42
+	assert.Equal(t, []int{1, 0, 0}, FlatExportedNamedStructFields(reflect.TypeOf(Outer{}), "json")[1].Index)
+	assert.Equal(t, []int{0}, FlatExportedNamedStructFields(reflect.TypeOf(Outer{}), "json")[1].Field.Index)
+	assert.Equal(t, []int{1, 0, 0}, FlatExportedStructFieldValueNameMap(o, "json")["value"].Index)
+	assert.Equal(t, []int{1, 0, 0}, FlatExportedStructFields(o)[1].Index)
+}
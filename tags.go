@@ -0,0 +1,189 @@
+package reflection
+
+import (
+	"reflect"
+	"strings"
+)
+
+// TagOption is a single option of a struct tag value.
+// Options are either flags like "omitempty"
+// or key/value pairs like "min=3".
+type TagOption struct {
+	Key      string // Key of the option or the flag name
+	Value    string // Unquoted value after the equal sign
+	HasValue bool   // If the option has an equal sign with a value
+}
+
+// String returns the option in the format it was parsed from,
+// quoting the value if necessary.
+func (o TagOption) String() string {
+	if !o.HasValue {
+		return o.Key
+	}
+	if strings.ContainsAny(o.Value, `,'"\`) {
+		return o.Key + "='" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(o.Value) + "'"
+	}
+	return o.Key + "=" + o.Value
+}
+
+// TagOptions is a list of parsed struct tag options
+// in the order of the tag value.
+type TagOptions []TagOption
+
+// Has returns if an option with key exists,
+// either as flag or as key/value pair.
+func (opts TagOptions) Has(key string) bool {
+	_, ok := opts.Lookup(key)
+	return ok
+}
+
+// Lookup returns the value of the first option with key
+// and true if such an option exists.
+// The value is empty for flag options without a value.
+func (opts TagOptions) Lookup(key string) (value string, ok bool) {
+	for _, o := range opts {
+		if o.Key == key {
+			return o.Value, true
+		}
+	}
+	return "", false
+}
+
+// Get returns the value of the first option with key
+// or an empty string if no such option exists.
+func (opts TagOptions) Get(key string) string {
+	value, _ := opts.Lookup(key)
+	return value
+}
+
+// String returns the options joined with commas.
+func (opts TagOptions) String() string {
+	var b strings.Builder
+	for i, o := range opts {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(o.String())
+	}
+	return b.String()
+}
+
+// Tag is a parsed struct tag value
+// consisting of a name followed by comma separated options.
+type Tag struct {
+	Name    string     // Part of the tag value before the first comma
+	Options TagOptions // Parsed options after the first comma
+}
+
+// IsIgnored returns true if the tag name is "-",
+// which is the convention for fields that should be ignored.
+func (t Tag) IsIgnored() bool {
+	return t.Name == "-"
+}
+
+// HasOption returns if the tag has an option with key,
+// either as flag or as key/value pair.
+func (t Tag) HasOption(key string) bool {
+	return t.Options.Has(key)
+}
+
+// Option returns the value of the first option with key
+// and true if such an option exists.
+func (t Tag) Option(key string) (value string, ok bool) {
+	return t.Options.Lookup(key)
+}
+
+// String returns the tag in the format it was parsed from.
+func (t Tag) String() string {
+	if len(t.Options) == 0 {
+		return t.Name
+	}
+	return t.Name + "," + t.Options.String()
+}
+
+// ParseTag parses a struct tag value of the format
+// "name,flag,key=value,key='quoted, value'"
+// into the name before the first comma and its options.
+//
+// Option values can be quoted with single or double quotes
+// to contain commas, backslashes escape the next character
+// within quoted values.
+//
+// Example:
+//
+//	tag := reflection.ParseTag(`name,omitempty,min=3,pattern='a,b'`)
+//	fmt.Println(tag.Name)                   // name
+//	fmt.Println(tag.HasOption("omitempty")) // true
+//	fmt.Println(tag.Options.Get("min"))     // 3
+//	fmt.Println(tag.Options.Get("pattern")) // a,b
+func ParseTag(value string) Tag {
+	name, options, _ := strings.Cut(value, ",")
+	return Tag{Name: name, Options: ParseTagOptions(options)}
+}
+
+// LookupTag parses the value of the tag with key of a struct field
+// using ParseTag and returns if the field has a tag with that key.
+//
+// Example:
+//
+//	field, _ := reflect.TypeOf(User{}).FieldByName("Email")
+//	tag, ok := reflection.LookupTag(field, "json")
+func LookupTag(field reflect.StructField, key string) (tag Tag, ok bool) {
+	value, ok := field.Tag.Lookup(key)
+	if !ok {
+		return Tag{}, false
+	}
+	return ParseTag(value), true
+}
+
+// ParseTagOptions parses comma separated options
+// like "required,min=3,oneof='a,b'" without a leading name.
+// See ParseTag for the syntax of the options.
+func ParseTagOptions(s string) (options TagOptions) {
+	for s != "" {
+		var option TagOption
+		end := strings.IndexAny(s, "=,")
+		if end == -1 {
+			option.Key, s = s, ""
+		} else {
+			option.Key = s[:end]
+			sep := s[end]
+			s = s[end+1:]
+			if sep == '=' {
+				option.Value, s = parseTagOptionValue(s)
+				option.HasValue = true
+			}
+		}
+		option.Key = strings.TrimSpace(option.Key)
+		if option.Key != "" || option.HasValue {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// parseTagOptionValue parses a possibly quoted option value
+// from the beginning of s and returns the rest of s after the next comma.
+func parseTagOptionValue(s string) (value, rest string) {
+	s = strings.TrimLeft(s, " ")
+	if s == "" || s[0] != '\'' && s[0] != '"' {
+		value, rest, _ = strings.Cut(s, ",")
+		return strings.TrimSpace(value), rest
+	}
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		case c == quote:
+			_, rest, _ = strings.Cut(s[i+1:], ",")
+			return b.String(), rest
+		default:
+			b.WriteByte(c)
+		}
+	}
+	// Unterminated quote, use the rest of s as value
+	return b.String(), ""
+}
@@ -0,0 +1,73 @@
+package reflection
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Tag
+	}{
+		{value: "", want: Tag{}},
+		{value: "name", want: Tag{Name: "name"}},
+		{value: "-", want: Tag{Name: "-"}},
+		{value: ",omitempty", want: Tag{Options: TagOptions{{Key: "omitempty"}}}},
+		{
+			value: "name,omitempty,min=3",
+			want: Tag{Name: "name", Options: TagOptions{
+				{Key: "omitempty"},
+				{Key: "min", Value: "3", HasValue: true},
+			}},
+		},
+		{
+			value: `name, pattern='a,b', default="x \"y\"",empty=,last`,
+			want: Tag{Name: "name", Options: TagOptions{
+				{Key: "pattern", Value: "a,b", HasValue: true},
+				{Key: "default", Value: `x "y"`, HasValue: true},
+				{Key: "empty", HasValue: true},
+				{Key: "last"},
+			}},
+		},
+		{
+			value: `name,oneof=a b c,unterminated='x,y`,
+			want: Tag{Name: "name", Options: TagOptions{
+				{Key: "oneof", Value: "a b c", HasValue: true},
+				{Key: "unterminated", Value: "x,y", HasValue: true},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseTag(tt.value))
+		})
+	}
+}
+
+func TestTag(t *testing.T) {
+	tag := ParseTag(`name,omitempty,min=3,pattern='a,b'`)
+	assert.Equal(t, "name", tag.Name)
+	assert.False(t, tag.IsIgnored())
+	assert.True(t, tag.HasOption("omitempty"))
+	assert.True(t, tag.HasOption("min"))
+	assert.False(t, tag.HasOption("max"))
+	value, ok := tag.Option("pattern")
+	assert.True(t, ok)
+	assert.Equal(t, "a,b", value)
+	assert.Equal(t, "", tag.Options.Get("omitempty"))
+	assert.Equal(t, `name,omitempty,min=3,pattern='a,b'`, tag.String())
+	assert.Equal(t, tag, ParseTag(tag.String()))
+
+	type Struct struct {
+		Field string `json:"field,omitempty"`
+	}
+	field := reflect.TypeOf(Struct{}).Field(0)
+	tag, ok = LookupTag(field, "json")
+	assert.True(t, ok)
+	assert.Equal(t, Tag{Name: "field", Options: TagOptions{{Key: "omitempty"}}}, tag)
+	_, ok = LookupTag(field, "db")
+	assert.False(t, ok)
+}
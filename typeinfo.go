@@ -0,0 +1,361 @@
+package reflection
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// structTypeInfo holds the metadata of a struct type
+// that is needed by the functions of this package.
+// It is built once per type by getStructTypeInfo
+// and must not be modified after that.
+type structTypeInfo struct {
+	typ reflect.Type
+	// fields are the direct fields of the struct type
+	fields []structFieldInfo
+}
+
+// structFieldInfo is a field of a struct type
+// together with its index path from the outermost struct type.
+type structFieldInfo struct {
+	Field  reflect.StructField
+	Index  []int
+	Groups []string    // Parsed by FieldValidationGroups
+	Rules  *fieldRules // Shared with the flattened copies of the field
+}
+
+// fieldRules caches the parsed validation rules of a struct field
+// by the key of the rules tag.
+type fieldRules struct {
+	byTag sync.Map // string -> TagOptions
+}
+
+// options returns the parsed options of the rulesTag tag of field.
+func (r *fieldRules) options(field reflect.StructField, rulesTag string) TagOptions {
+	if options, ok := r.byTag.Load(rulesTag); ok {
+		return options.(TagOptions)
+	}
+	options, _ := r.byTag.LoadOrStore(rulesTag, ParseTagOptions(field.Tag.Get(rulesTag)))
+	return options.(TagOptions)
+}
+
+// indexBuffer is the backing array for copies of the index paths
+// of fields that don't share memory with the cached field information,
+// so that callers can modify the returned slices.
+// It allocates only once for all fields of a struct type.
+type indexBuffer []int
+
+func newIndexBuffer(fields []structFieldInfo) indexBuffer {
+	n := 0
+	for i := range fields {
+		n += len(fields[i].Field.Index) + len(fields[i].Index)
+	}
+	return make(indexBuffer, 0, n)
+}
+
+// clone returns a copy of the field and its index path
+// with the index slices carved from the buffer.
+func (b *indexBuffer) clone(f *structFieldInfo) (field reflect.StructField, index []int) {
+	field = f.Field
+	field.Index = b.carve(f.Field.Index)
+	return field, b.carve(f.Index)
+}
+
+// carve returns a copy of index from the buffer
+// with a capacity limited to its length,
+// so that appending to it doesn't overwrite other copies.
+func (b *indexBuffer) carve(index []int) []int {
+	start := len(*b)
+	*b = append(*b, index...)
+	return (*b)[start:len(*b):len(*b)]
+}
+
+// Depth returns the embedding depth of the field,
+// 0 for direct fields of the outermost struct type.
+func (f *structFieldInfo) Depth() int {
+	return len(f.Index) - 1
+}
+
+// structTagInfo holds the parsed tags for one NameResolver
+// and the flattened fields resolved by the names from those tags.
+type structTagInfo struct {
+	// fields are the parsed tags of structTypeInfo.fields
+	// with the same slice indices
+	fields []fieldTag
+	// flatFields are the fields of the struct type
+	// with anonymous embedded fields flattened
+	// following the selector rules of Go
+	flatFields []structFieldInfo
+	// flatTags are the parsed tags of flatFields
+	// with the same slice indices
+	flatTags []fieldTag
+	// ambiguous are the names of fields that were dropped
+	// from flatFields because they were ambiguous
+	ambiguous []AmbiguousStructField
+}
+
+// fieldTag is the parsed value of a struct field tag.
+type fieldTag struct {
+	Tag
+	Found     bool   // If the field has a tag with one of the keys of the NameResolver
+	FieldName string // Name of the tag or the Go field name resolved by the NameResolver
+}
+
+var (
+	structTypeInfos sync.Map // reflect.Type -> *structTypeInfo
+	noFlattenTypes  sync.Map // reflect.Type -> struct{}
+)
+
+func init() {
+	RegisterNoFlattenType(reflect.TypeFor[time.Time]())
+}
+
+// RegisterNoFlattenType registers struct types that are not flattened
+// when embedded anonymously in another struct.
+// Instead the embedded field is handled like a named field
+// with the name of the type.
+//
+// The type time.Time is registered by default.
+//
+// Example:
+//
+//	type Timestamp struct {
+//	    time.Time
+//	}
+//	type Event struct {
+//	    Timestamp
+//	    Name string
+//	}
+//	reflection.RegisterNoFlattenType(reflect.TypeFor[Timestamp]())
+//	names := reflection.FlatStructFieldNames(reflect.TypeOf(Event{}))
+//	fmt.Println(names) // [Timestamp Name]
+func RegisterNoFlattenType(types ...reflect.Type) {
+	for _, t := range types {
+		noFlattenTypes.Store(DerefType(t), struct{}{})
+	}
+	// The flattened fields of all cached types have to be resolved again
+	structTypeInfos.Clear()
+}
+
+// getStructTypeInfo returns the cached structTypeInfo for the struct type t.
+// t must not be a pointer type.
+func getStructTypeInfo(t reflect.Type) *structTypeInfo {
+	if info, ok := structTypeInfos.Load(t); ok {
+		return info.(*structTypeInfo)
+	}
+	numField := t.NumField()
+	info := &structTypeInfo{
+		typ:    t,
+		fields: make([]structFieldInfo, numField),
+	}
+	for i := range numField {
+		field := t.Field(i)
+		info.fields[i] = structFieldInfo{
+			Field:  field,
+			Index:  field.Index,
+			Groups: FieldValidationGroups(field),
+			Rules:  new(fieldRules),
+		}
+	}
+	actual, _ := structTypeInfos.LoadOrStore(t, info)
+	return actual.(*structTypeInfo)
+}
+
+// getFlatFields returns the flattened fields of the struct type t
+// resolved by their Go names.
+func getFlatFields(t reflect.Type) []structFieldInfo {
+	return getStructTypeInfo(t).tagInfo(tagNameResolver("")).flatFields
+}
+
+// tagInfo returns the parsed tags of the NameResolver
+// for the fields of the struct type.
+// They are cached by the NameResolver, so that the cache
+// is garbage collected together with the NameResolver.
+func (info *structTypeInfo) tagInfo(r *NameResolver) *structTagInfo {
+	if r == nil {
+		r = tagNameResolver("")
+	}
+	if tags, ok := r.tagInfos.Load(info); ok {
+		return tags.(*structTagInfo)
+	}
+	tags := &structTagInfo{fields: make([]fieldTag, len(info.fields))}
+	for i := range info.fields {
+		tags.fields[i] = parseFieldTag(info.fields[i].Field, r)
+	}
+	tags.flatFields, tags.flatTags, tags.ambiguous = resolveFlatFields(info.typ, r)
+	actual, _ := r.tagInfos.LoadOrStore(info, tags)
+	return actual.(*structTagInfo)
+}
+
+func parseFieldTag(field reflect.StructField, r *NameResolver) fieldTag {
+	tag := r.lookupTag(field)
+	tag.FieldName = r.fieldName(field, tag)
+	return tag
+}
+
+// flattenEmbedded returns if the fields of the struct field
+// with the dereferenced type ft should be flattened.
+// Only anonymous embedded struct fields are flattened
+// and only if their type was not registered with RegisterNoFlattenType
+// and they are not tagged with `reflection:"noflatten"`.
+func flattenEmbedded(field reflect.StructField, ft reflect.Type) bool {
+	if !field.Anonymous || ft.Kind() != reflect.Struct {
+		return false
+	}
+	if _, noFlatten := noFlattenTypes.Load(ft); noFlatten {
+		return false
+	}
+	return !ParseTagOptions(field.Tag.Get("reflection")).Has("noflatten")
+}
+
+// flatFieldCandidate is a possible flattened field
+// before the dominant fields are selected by resolveFlatFields.
+type flatFieldCandidate struct {
+	structFieldInfo
+	tag    fieldTag
+	name   string
+	tagged bool
+}
+
+// resolveFlatFields returns the flattened fields of the struct type t
+// following the selector rules of Go for embedded fields,
+// extended by the handling of tagged names of encoding/json:
+//   - A field at a shallower embedding depth shadows fields
+//     with the same name at deeper depths
+//   - Fields with the same name at the same depth are ambiguous
+//     and are all dropped, except if exactly one of them has
+//     its name from a tag of r, then that field is used
+//
+// The name of a field is resolved by r from its tags
+// or the Go field name. Fields with the tag name "-" don't take
+// part in the name resolution and are always returned.
+// Like with encoding/json, the same applies to unexported
+// non-embedded fields if r has tags.
+// Anonymous embedded structs with a name from a tag are not flattened.
+func resolveFlatFields(t reflect.Type, r *NameResolver) (fields []structFieldInfo, tags []fieldTag, ambiguous []AmbiguousStructField) {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	var (
+		candidates []flatFieldCandidate
+		current    []embedded
+		next       = []embedded{{typ: t}}
+		count      map[reflect.Type]int
+		nextCount  = map[reflect.Type]int{}
+		visited    = map[reflect.Type]bool{}
+	)
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, e := range current {
+			if visited[e.typ] {
+				// Fields of a type visited at a shallower
+				// depth are shadowed by the fields found there
+				continue
+			}
+			visited[e.typ] = true
+
+			for _, f := range getStructTypeInfo(e.typ).fields {
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = f.Field.Index[0]
+
+				tag := parseFieldTag(f.Field, r)
+				// Like with encoding/json, an embedded struct with a name tag
+				// is handled like a named field instead of being flattened
+				if ft := DerefType(f.Field.Type); tag.Name == "" && flattenEmbedded(f.Field, ft) {
+					nextCount[ft]++
+					if nextCount[ft] == 1 {
+						next = append(next, embedded{typ: ft, index: index})
+					}
+					continue
+				}
+
+				c := flatFieldCandidate{
+					structFieldInfo: structFieldInfo{Field: f.Field, Index: index, Groups: f.Groups, Rules: f.Rules},
+					tag:             tag,
+					name:            tag.FieldName,
+					tagged:          tag.Name != "",
+				}
+				if len(r.Tags) > 0 && !f.Field.IsExported() && !f.Field.Anonymous {
+					// Unexported fields don't shadow promoted fields
+					// with tag names, like with encoding/json
+					c.name = "-"
+				}
+				candidates = append(candidates, c)
+				if count[e.typ] > 1 {
+					// The same type is embedded multiple times at the same depth,
+					// add the candidate again to make its name ambiguous
+					candidates = append(candidates, c)
+				}
+			}
+		}
+	}
+
+	// Sort by name, then by depth, then tagged names first
+	slices.SortStableFunc(candidates, func(a, b flatFieldCandidate) int {
+		if c := strings.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(len(a.Index), len(b.Index)); c != 0 {
+			return c
+		}
+		if a.tagged != b.tagged {
+			if a.tagged {
+				return -1
+			}
+			return 1
+		}
+		return slices.Compare(a.Index, b.Index)
+	})
+
+	dominant := make([]flatFieldCandidate, 0, len(candidates))
+	for i := 0; i < len(candidates); {
+		name := candidates[i].name
+		end := i + 1
+		for end < len(candidates) && candidates[end].name == name {
+			end++
+		}
+		group := candidates[i:end]
+		i = end
+
+		switch {
+		case name == "-":
+			dominant = append(dominant, group...)
+		case len(group) == 1 || len(group[0].Index) < len(group[1].Index) || group[0].tagged && !group[1].tagged:
+			dominant = append(dominant, group[0])
+		default:
+			a := AmbiguousStructField{Name: name, Depth: len(group[0].Index) - 1}
+			for j, c := range group {
+				if len(c.Index) != len(group[0].Index) || c.tagged != group[0].tagged {
+					break
+				}
+				if j == 0 || !slices.Equal(c.Index, group[j-1].Index) {
+					a.Fields = append(a.Fields, c.Field)
+				}
+			}
+			ambiguous = append(ambiguous, a)
+		}
+	}
+
+	// Restore the field order of the struct definition
+	slices.SortFunc(dominant, func(a, b flatFieldCandidate) int {
+		return slices.Compare(a.Index, b.Index)
+	})
+	dominant = slices.CompactFunc(dominant, func(a, b flatFieldCandidate) bool {
+		return slices.Equal(a.Index, b.Index)
+	})
+	fields = make([]structFieldInfo, len(dominant))
+	tags = make([]fieldTag, len(dominant))
+	for i := range dominant {
+		fields[i] = dominant[i].structFieldInfo
+		tags[i] = dominant[i].tag
+	}
+	return fields, tags, ambiguous
+}
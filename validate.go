@@ -1,10 +1,17 @@
 package reflection
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"iter"
 	"reflect"
+	"runtime"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // IsZero returns true if the underlying value of v is the zero (default) value of its type,
@@ -24,116 +31,407 @@ func IsZero(v any) bool {
 	return v == nil || reflect.DeepEqual(v, reflect.Zero(reflect.TypeOf(v)).Interface())
 }
 
+// ValidateOptions are the options for ValidateStructFieldsWithOptions
+// and ZeroValueExportedStructFieldNamesWithOptions.
+type ValidateOptions struct {
+	// NamePrefix is added to all field names
+	NamePrefix string
+
+	// NameTag is the struct tag key to use for field names (e.g., "json").
+	// If empty or not found, uses the Go field name.
+	NameTag string
+
+	// NameResolver is used for field names instead of NameTag if not nil
+	NameResolver *NameResolver
+
+	// NamesToValidate is an optional list of specific field names
+	// or patterns to validate that are added to Fields.Include.
+	// If empty, all fields are validated.
+	NamesToValidate []string
+
+	// Fields selects the fields to validate
+	// with include and exclude patterns and a filter function.
+	// The zero value selects all fields.
+	Fields FieldSelector
+
+	// Groups are the active validation groups.
+	// If not empty, then only fields belonging to one of the groups
+	// listed in their `groups` struct tag like `groups:"create,update"`
+	// are validated. Fields without a groups tag belong to DefaultValidationGroup.
+	// The nested fields of struct fields not belonging to the groups
+	// are still validated if they belong to the groups.
+	Groups []string
+
+	// PresentOnly skips fields with nil values as defined by IsNil
+	// together with their nested fields.
+	// Use it for partial updates like HTTP PATCH requests
+	// decoded into structs with pointer fields
+	// where absent fields are nil.
+	PresentOnly bool
+
+	// PresentFields are the names of the fields that are present
+	// in a partial update, for example the paths of a JSON request body
+	// returned by PresentJSONPaths.
+	// If not nil, then only present fields and their nested fields
+	// are validated, including zero values explicitly set by the client.
+	// The parents of present nested fields are traversed
+	// but not validated themselves unless they are also present.
+	// The names are matched without NamePrefix and case-insensitively
+	// like encoding/json matches JSON keys to struct fields,
+	// map keys can be written like field names ("attrs.key" for `attrs["key"]`).
+	PresentFields []string
+
+	// NestEmbedded disables the flattening of anonymous embedded structs.
+	// Embedded structs are handled like named sub-structs
+	// with the name of the embedded type as prefix (e.g., "Base.ID").
+	NestEmbedded bool
+
+	// RulesTag is the struct tag key of declarative validation rules
+	// like `validate:"required,min=3"` that are checked
+	// by ValidateStructFieldsWithOptions before calling the validation function.
+	// If empty, no rules are checked. See ValidateStructRules.
+	RulesTag string
+
+	// ValidateField is an optional function that is called
+	// for every struct field after the rules were checked.
+	// In contrast to the validation function it gets the
+	// parent struct and the root value with the field
+	// to validate fields depending on other fields.
+	ValidateField func(field ValidationField) error
+
+	// StopAtFirstError stops the validation after the first FieldError
+	StopAtFirstError bool
+
+	// SkipValidatorMethods disables calling the methods
+	// of the Validator and ContextValidator interfaces
+	// implemented by the struct, its fields, and their elements.
+	SkipValidatorMethods bool
+}
+
+func (opts *ValidateOptions) nameResolver() *NameResolver {
+	if opts.NameResolver != nil {
+		return opts.NameResolver
+	}
+	return tagNameResolver(opts.NameTag)
+}
+
 // ZeroValueExportedStructFieldNames returns the names of exported struct fields that have zero (default) values.
 //
 // Parameters:
 //   - st: The struct value to examine (can be a struct, pointer to struct, or reflect.Value)
 //   - namePrefix: A prefix to add to all returned field names
-//   - nameTag: The struct tag key to use for field names (e.g., "json"). If empty or not found, uses Go field name
-//   - namesToValidate: Optional list of specific field names to check. If empty, checks all fields
+//   - nameTag: The struct tag key to use for field names (e.g., "json") or a *NameResolver. If empty or not found, uses Go field name
+//   - namesToValidate: Optional list of specific field names or patterns to check, see FieldSelector.
+//     If empty, checks all fields
 //
 // Behavior:
-//   - Anonymous embedded structs are flattened
+//   - Anonymous embedded structs are flattened like with FlatExportedStructFields,
+//     the fields of nil embedded struct pointers are reported as zero
 //   - Named sub-structs are checked recursively with their name as prefix (e.g., "Address.Street")
 //   - Zero elements in arrays/slices are reported with index notation (e.g., "Items[1]")
+//   - Struct and pointer to struct elements of arrays/slices are checked recursively
+//     with the element as prefix (e.g., "Addresses[2].Street"),
+//     nested slices with multiple indices (e.g., "Matrix[1][3]")
+//   - Zero entries of maps are reported with the quoted key for string keys (e.g., `Attrs["key"]`)
+//     or the formatted key for other key types (e.g., "Counts[42]") in sorted key order
+//   - Struct and map entries of maps are checked recursively with the entry as prefix (e.g., `Addrs["home"].Street`)
 //   - Struct tag values can include comma-separated options; only the part before the comma is used
 //   - Fields with tag value "-" are ignored
 //
 // Example:
 //
 //	type Form struct {
-//	    Name  string   `json:"name"`
-//	    Email string   `json:"email"`
-//	    Age   int      `json:"age"`
-//	    Tags  []string `json:"tags"`
+//	    Name  string            `json:"name"`
+//	    Email string            `json:"email"`
+//	    Age   int               `json:"age"`
+//	    Tags  []string          `json:"tags"`
+//	    Attrs map[string]string `json:"attrs"`
 //	}
 //
-//	form := Form{Name: "John", Tags: []string{"a", "", "c"}}
+//	form := Form{Name: "John", Tags: []string{"a", "", "c"}, Attrs: map[string]string{"x": ""}}
 //	zeros := reflection.ZeroValueExportedStructFieldNames(form, "", "json")
-//	// zeros: ["email", "age", "tags[1]"]
-func ZeroValueExportedStructFieldNames(st any, namePrefix, nameTag string, namesToValidate ...string) (zeroNames []string) {
+//	// zeros: ["email", "age", "tags[1]", `attrs["x"]`]
+//
+// Use ZeroValueExportedStructFieldNamesWithOptions with ValidateOptions.NestEmbedded
+// to report the fields of embedded structs with the name of the embedded struct as prefix.
+func ZeroValueExportedStructFieldNames[N NameSource](st any, namePrefix string, nameTag N, namesToValidate ...string) (zeroNames []string) {
+	return ZeroValueExportedStructFieldNamesWithOptions(st, ValidateOptions{
+		NamePrefix:      namePrefix,
+		NameResolver:    nameResolverOf(nameTag),
+		NamesToValidate: namesToValidate,
+	})
+}
+
+// ZeroValueExportedStructFieldNamesWithOptions returns the names of exported struct fields
+// that have zero (default) values like ZeroValueExportedStructFieldNames
+// using the passed options.
+//
+// Example:
+//
+//	type Base struct {
+//	    ID int `json:"id"`
+//	}
+//	type User struct {
+//	    Base
+//	    Name string `json:"name"`
+//	}
+//
+//	zeros := reflection.ZeroValueExportedStructFieldNamesWithOptions(User{}, reflection.ValidateOptions{
+//	    NameTag:      "json",
+//	    NestEmbedded: true,
+//	})
+//	// zeros: ["Base.id", "name"]
+func ZeroValueExportedStructFieldNamesWithOptions(st any, opts ValidateOptions) (zeroNames []string) {
 	v, t := DerefValueAndType(st)
 	if t.Kind() != reflect.Struct {
 		panic(fmt.Errorf("%T is not a struct or pointer to a struct", st))
 	}
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		if !field.IsExported() {
-			continue
+	vd := newValidator(nil, &opts)
+	vd.root = v
+	return vd.appendZeroValueStructFieldNames(nil, v, opts.NamePrefix)
+}
+
+// validator holds the options for the recursive validation
+// and zero value detection of struct fields.
+type validator struct {
+	ctx                 context.Context
+	validateFunc        func(any) error
+	validateContextFunc func(context.Context, ValidationField) error
+	names               *NameResolver
+	selector            *fieldSelector
+	groups              []string
+	presentOnly         bool
+	present             presentFields
+	namePrefix          string
+	nestEmbedded        bool
+	rulesTag            string
+	validateField       func(ValidationField) error
+	stopAtFirstError    bool
+	validatorMethods    bool
+	root                reflect.Value
+	// visiting are the pointers to structs on the current path
+	// to stop the recursion at pointer cycles
+	visiting map[visitKey]struct{}
+	// reported are the names of the fields with FieldErrors
+	// returned by validator methods that are not validated again
+	reported map[string]struct{}
+	// parent is the validator calling the validator method
+	// that started this validator on the same goroutine
+	parent *validator
+	// goroutine is the ID of the goroutine if parent was looked up
+	goroutine uint64
+	// method identifies the value of the running validator method call
+	method visitKey
+	// failed is set when the first FieldError was appended
+	failed bool
+}
+
+// visitKey identifies a value by its address and type.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// onPath returns if v is a non nil pointer to a struct that is the root
+// or is already on the current path because of a pointer cycle.
+// The path includes the paths of the parent validators.
+func (vd *validator) onPath(v reflect.Value) bool {
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Type().Elem().Kind() != reflect.Struct {
+		return false
+	}
+	key := visitKey{ptr: v.Pointer(), typ: v.Type().Elem()}
+	for p := vd; p != nil; p = p.parent {
+		if p.root.CanAddr() && p.root.UnsafeAddr() == key.ptr && p.root.Type() == key.typ {
+			return true
 		}
-		fieldName, ext := getFieldName(field, namePrefix, nameTag)
-		if ignoreField(namesToValidate, fieldName, ext) {
-			continue
+		if _, ok := p.visiting[key]; ok {
+			return true
 		}
+	}
+	return false
+}
 
-		fieldVal := v.Field(i)
+// enterPointer returns false if the struct that the non nil pointer v
+// points to is on the current path, see onPath,
+// else it adds v to the current path until leavePointer is called.
+func (vd *validator) enterPointer(v reflect.Value) bool {
+	if vd.onPath(v) {
+		return false
+	}
+	key := visitKey{ptr: v.Pointer(), typ: v.Type().Elem()}
+	if vd.visiting == nil {
+		vd.visiting = make(map[visitKey]struct{})
+	}
+	vd.visiting[key] = struct{}{}
+	return true
+}
 
-		switch kind := fieldVal.Kind(); kind {
-		case reflect.Ptr:
-			if fieldVal.IsNil() {
-				zeroNames = append(zeroNames, fieldName)
-				continue
-			}
-			if fieldVal.Type().Elem().Kind() == reflect.Struct {
-				zeroNames = append(zeroNames, ZeroValueExportedStructFieldNames(fieldVal.Interface(), fieldName+".", nameTag, namesToValidate...)...)
-				continue
-			}
+func (vd *validator) leavePointer(v reflect.Value) {
+	delete(vd.visiting, visitKey{ptr: v.Pointer(), typ: v.Type().Elem()})
+}
 
-		case reflect.Struct:
-			if fieldVal.CanAddr() {
-				// Use pointer if possible to avoid copy of struct
-				fieldVal = fieldVal.Addr()
-			}
-			zeroNames = append(zeroNames, ZeroValueExportedStructFieldNames(fieldVal.Interface(), fieldName+".", nameTag, namesToValidate...)...)
-			continue
+// newValidator parses the options once,
+// the returned validator can be reused with validateStruct.
+func newValidator(validateFunc func(any) error, opts *ValidateOptions) *validator {
+	return &validator{
+		validateFunc:     validateFunc,
+		names:            opts.nameResolver(),
+		selector:         newFieldSelector(&opts.Fields, opts.NamesToValidate),
+		groups:           opts.Groups,
+		presentOnly:      opts.PresentOnly,
+		present:          newPresentFields(opts.PresentFields),
+		namePrefix:       opts.NamePrefix,
+		nestEmbedded:     opts.NestEmbedded,
+		rulesTag:         opts.RulesTag,
+		validateField:    opts.ValidateField,
+		stopAtFirstError: opts.StopAtFirstError,
+		validatorMethods: !opts.SkipValidatorMethods,
+	}
+}
 
-		case reflect.Slice, reflect.Array:
-			if kind == reflect.Slice && fieldVal.IsNil() {
-				zeroNames = append(zeroNames, fieldName)
-				continue
-			}
-			for j := 0; j < fieldVal.Len(); j++ {
-				if IsZero(fieldVal.Index(j).Interface()) {
-					zeroNames = append(zeroNames, fmt.Sprintf("%s[%d]", fieldName, j))
+// structFields returns an iterator over the exported fields
+// of the struct value v with namePrefix prepended to their names
+// and goPrefix prepended to their Go names
+// that are selected or traversed by the field selector.
+// The fields of anonymous embedded structs are flattened
+// unless nestEmbedded is set.
+// The values of fields of nil embedded struct pointers are invalid.
+func (vd *validator) structFields(v reflect.Value, namePrefix, goPrefix string) iter.Seq[ValidationField] {
+	return func(yield func(ValidationField) bool) {
+		info := getStructTypeInfo(v.Type())
+		tags := info.tagInfo(vd.names)
+		if vd.nestEmbedded {
+			for i := range info.fields {
+				field := &info.fields[i]
+				name, valid := exportedFieldName(field.Field, tags.fields[i])
+				if !valid {
+					continue
+				}
+				f, traverse := vd.selectField(namePrefix+name, goPrefix+field.Field.Name, field, tags.fields[i].Tag, v.Field(i), v)
+				if traverse && !yield(f) {
+					return
 				}
 			}
-			continue
-
-		case reflect.Map:
-			if fieldVal.IsNil() {
-				zeroNames = append(zeroNames, fieldName)
+			return
+		}
+		for i := range tags.flatFields {
+			name, valid := exportedFieldName(tags.flatFields[i].Field, tags.flatTags[i])
+			if !valid {
 				continue
 			}
-			panic("TODO")
-		}
-
-		if IsZero(fieldVal.Interface()) {
-			zeroNames = append(zeroNames, fieldName)
+			fieldVal, _ := flatFieldValue(v, tags.flatFields[i].Index, NilEmbeddedInvalid)
+			field := &tags.flatFields[i]
+			f, traverse := vd.selectField(namePrefix+name, goPrefix+field.Field.Name, field, tags.flatTags[i].Tag, fieldVal, v)
+			if traverse && !yield(f) {
+				return
+			}
 		}
 	}
+}
 
-	return zeroNames
+// selectField returns the ValidationField for a struct field
+// and if it is selected or has to be traversed
+// because its nested fields are selected.
+func (vd *validator) selectField(name, goName string, field *structFieldInfo, tag Tag, value, parent reflect.Value) (f ValidationField, traverse bool) {
+	if vd.presentOnly && IsNil(value) {
+		return ValidationField{}, false
+	}
+	present, traverse := vd.present.lookup(strings.TrimPrefix(name, vd.namePrefix))
+	if !traverse {
+		return ValidationField{}, false
+	}
+	selected, traverse := vd.selector.selectField(name, field.Field)
+	if !traverse {
+		return ValidationField{}, false
+	}
+	selected = selected && present
+	f = vd.field(name, goName, field.Field, tag, value, parent)
+	f.rules = field.Rules
+	f.traverseOnly = !selected || !inValidationGroups(field.Groups, vd.groups)
+	if _, reported := vd.reported[name]; reported {
+		// Already validated by the validator method of a parent
+		f.traverseOnly = true
+	}
+	return f, true
 }
 
-func getFieldName(field reflect.StructField, namePrefix, nameTag string) (name string, ext string) {
-	name = field.Tag.Get(nameTag)
-	if comma := strings.IndexByte(name, ','); comma != -1 {
-		name, ext = name[:comma], name[comma+1:]
+func (vd *validator) field(name, goName string, field reflect.StructField, tag Tag, value, parent reflect.Value) ValidationField {
+	return ValidationField{
+		Name:   name,
+		GoName: goName,
+		Field:  field,
+		Tag:    tag,
+		Value:  value,
+		Parent: parent,
+		Root:   vd.root,
+		names:  vd.names,
 	}
-	if name == "" {
-		name = field.Name
+}
+
+func (vd *validator) appendZeroValueStructFieldNames(zeroNames []string, v reflect.Value, namePrefix string) []string {
+	for f := range vd.structFields(v, namePrefix, namePrefix) {
+		if !f.Value.IsValid() {
+			// Field of a nil embedded struct pointer
+			if !f.traverseOnly {
+				zeroNames = append(zeroNames, f.Name)
+			}
+			continue
+		}
+		zeroNames = vd.appendZeroValueNames(zeroNames, f.Value, f.Name, f.traverseOnly)
 	}
-	return namePrefix + name, ext
+	return zeroNames
 }
 
-func ignoreField(namesToValidate []string, name, _ string) bool {
-	if len(namesToValidate) == 0 {
-		return strings.Contains(name, "-")
+// appendZeroValueNames appends name to zeroNames if v is zero,
+// or the names of the zero fields and entries of v
+// if v is a struct, slice, array, or map.
+// If traverseOnly is true, then only the names
+// of selected nested struct fields are appended.
+func (vd *validator) appendZeroValueNames(zeroNames []string, v reflect.Value, name string, traverseOnly bool) []string {
+	switch kind := v.Kind(); kind {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return appendIf(zeroNames, name, !traverseOnly)
+		}
+		if v.Type().Elem().Kind() == reflect.Struct {
+			if vd.enterPointer(v) {
+				zeroNames = vd.appendZeroValueStructFieldNames(zeroNames, v.Elem(), name+".")
+				vd.leavePointer(v)
+			}
+			return zeroNames
+		}
+
+	case reflect.Struct:
+		return vd.appendZeroValueStructFieldNames(zeroNames, v, name+".")
+
+	case reflect.Slice, reflect.Array:
+		if kind == reflect.Slice && v.IsNil() {
+			return appendIf(zeroNames, name, !traverseOnly)
+		}
+		for j := 0; j < v.Len(); j++ {
+			zeroNames = vd.appendZeroValueNames(zeroNames, v.Index(j), fmt.Sprintf("%s[%d]", name, j), traverseOnly)
+		}
+		return zeroNames
+
+	case reflect.Map:
+		if v.IsNil() {
+			return appendIf(zeroNames, name, !traverseOnly)
+		}
+		for _, key := range sortedMapKeys(v) {
+			entryName := name + KeySegment(key).String()
+			zeroNames = vd.appendZeroValueNames(zeroNames, v.MapIndex(key), entryName, traverseOnly)
+		}
+		return zeroNames
 	}
-	if slices.Contains(namesToValidate, name) {
-		return strings.Contains(name, "-")
+
+	return appendIf(zeroNames, name, !traverseOnly && IsZero(v.Interface()))
+}
+
+func appendIf(names []string, name string, condition bool) []string {
+	if condition {
+		return append(names, name)
 	}
-	return true
+	return names
 }
 
 func validate(validateFunc func(any) error, v reflect.Value) error {
@@ -151,16 +449,280 @@ func validate(validateFunc func(any) error, v reflect.Value) error {
 	return err
 }
 
-// FieldError represents a validation error for a specific struct field.
-// It combines the field name with its validation error.
-type FieldError struct {
-	FieldName  string // Name of the field that failed validation
-	FieldError error  // The validation error for this field
+// validate calls the validation function for the field
+// or returns nil if there is no validation function
+// because only rules are checked or the validation was stopped.
+func (vd *validator) validate(f ValidationField) error {
+	switch {
+	case vd.stopped():
+		return nil
+	case vd.validateContextFunc != nil:
+		return vd.validateContextFunc(vd.ctx, f)
+	case vd.validateFunc != nil:
+		return validate(vd.validateFunc, f.Value)
+	}
+	return nil
+}
+
+// Validator is implemented by types that validate themselves.
+// The Validate method is called automatically by ValidateStructFields
+// and the other validation functions of this package
+// for the validated struct, its fields, and their elements.
+//
+// If Validate returns FieldErrors or a FieldError,
+// then their names are prefixed with the path of the validated field
+// and the fields with those names are not validated again.
+//
+// Validate can validate the fields of its own value with ValidateStructFields,
+// the method is not called again for a value while it is running for that value,
+// or for a value of the same type that is not addressable.
+type Validator interface {
+	Validate() error
+}
+
+var (
+	validatorType        = reflect.TypeFor[Validator]()
+	contextValidatorType = reflect.TypeFor[ContextValidator]()
+)
+
+// ContextValidator is implemented by types that validate themselves using a context.
+// ValidateStructFieldsContext prefers ValidateContext over Validate
+// if a type implements both interfaces, the other validation functions
+// prefer Validate and call ValidateContext with context.Background().
+// See Validator.
+type ContextValidator interface {
+	ValidateContext(ctx context.Context) error
+}
+
+// rootField returns the ValidationField for the validated root struct
+// named by the name prefix without the trailing dot.
+func (vd *validator) rootField(namePrefix string) ValidationField {
+	name := strings.TrimSuffix(namePrefix, ".")
+	return ValidationField{Name: name, GoName: name, Value: vd.root, Root: vd.root, names: vd.names}
+}
+
+// appendValidatorErrors calls the method of the Validator or ContextValidator
+// interface implemented by the field value, its address, or the value it points to,
+// and appends the returned errors.
+func (vd *validator) appendValidatorErrors(fieldErrors []FieldError, f ValidationField) []FieldError {
+	if !vd.validatorMethods || vd.stopped() {
+		return fieldErrors
+	}
+	switch err := vd.callValidatorMethod(f.Value).(type) {
+	case nil:
+		return fieldErrors
+	case FieldErrors:
+		return vd.appendReported(fieldErrors, f, err...)
+	case FieldError:
+		return vd.appendReported(fieldErrors, f, err)
+	default:
+		return vd.appendError(fieldErrors, f, err)
+	}
+}
+
+// appendReported appends the FieldErrors returned by the validator method
+// of the field and remembers their names so that the fields are not validated again.
+// Errors for fields already reported by the method of a parent are skipped.
+func (vd *validator) appendReported(fieldErrors []FieldError, f ValidationField, reported ...FieldError) []FieldError {
+	n := len(fieldErrors)
+	for _, fieldErr := range reported {
+		fieldErr = fieldErr.withPrefix(f.Name, f.GoName)
+		if _, ok := vd.reported[fieldErr.FieldName]; !ok {
+			fieldErrors = append(fieldErrors, fieldErr)
+		}
+	}
+	if len(fieldErrors) > n && vd.reported == nil {
+		vd.reported = make(map[string]struct{})
+	}
+	for _, fieldErr := range fieldErrors[n:] {
+		vd.reported[fieldErr.FieldName] = struct{}{}
+	}
+	vd.failed = vd.failed || len(fieldErrors) > n
+	return fieldErrors
+}
+
+// callValidatorMethod calls the Validate or ValidateContext method
+// of the first of the value, its address, or the value it points to
+// that implements one of the interfaces and returns its error.
+// The method is not called if the value is validated again from its own method.
+func (vd *validator) callValidatorMethod(v reflect.Value) error {
+	method := vd.validatorMethod(v)
+	if method == nil {
+		return nil
+	}
+	key := validatorMethodKey(v)
+	if vd.inValidatorMethod(key) {
+		return nil
+	}
+	// Validators started by the method on this goroutine
+	// find this validator as their parent
+	if vd.goroutine == 0 {
+		vd.goroutine = goroutineID()
+	}
+	vd.method = key
+	activeValidators.set(vd.goroutine, vd)
+	defer func() {
+		activeValidators.set(vd.goroutine, vd.parent)
+		vd.method = visitKey{}
+	}()
+	return method()
+}
+
+// validatorMethodKey identifies the value of a validator method call
+// by its address and type, or only by its type if it is not addressable,
+// like the copies passed to methods with value receivers.
+func validatorMethodKey(v reflect.Value) visitKey {
+	switch {
+	case v.Kind() == reflect.Ptr && !v.IsNil():
+		return visitKey{ptr: v.Pointer(), typ: v.Type().Elem()}
+	case v.CanAddr():
+		return visitKey{ptr: v.UnsafeAddr(), typ: v.Type()}
+	default:
+		return visitKey{typ: v.Type()}
+	}
 }
 
-// Error implements the error interface, formatting the error as "FieldName: error message".
-func (f FieldError) Error() string {
-	return f.FieldName + ": " + f.FieldError.Error()
+// inValidatorMethod returns if a parent validator
+// is running the validator method for the value of key.
+func (vd *validator) inValidatorMethod(key visitKey) bool {
+	for p := vd.parent; p != nil; p = p.parent {
+		if p.method == key || key.ptr == 0 && p.method.typ == key.typ {
+			return true
+		}
+	}
+	return false
+}
+
+// validatorMethod returns the Validate or ValidateContext method
+// of the first of the value, its address, or the value it points to
+// that implements one of the interfaces or nil.
+func (vd *validator) validatorMethod(v reflect.Value) func() error {
+	candidates := [3]reflect.Value{v}
+	if v.CanAddr() {
+		candidates[1] = v.Addr()
+	}
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		candidates[2] = v.Elem()
+	}
+	for _, c := range candidates {
+		if !c.IsValid() || !c.CanInterface() || c.Kind() == reflect.Ptr && c.IsNil() {
+			continue
+		}
+		if !c.Type().Implements(validatorType) && !c.Type().Implements(contextValidatorType) {
+			// Don't allocate for the interface conversion
+			continue
+		}
+		i := c.Interface()
+		if cv, ok := i.(ContextValidator); ok && vd.ctx != nil {
+			return func() error { return cv.ValidateContext(vd.ctx) }
+		}
+		if val, ok := i.(Validator); ok {
+			return val.Validate
+		}
+		if cv, ok := i.(ContextValidator); ok {
+			return func() error { return cv.ValidateContext(context.Background()) }
+		}
+	}
+	return nil
+}
+
+// activeValidators are the validators running validator methods
+// by the ID of their goroutine.
+var activeValidators validatorsByGoroutine
+
+type validatorsByGoroutine struct {
+	count      atomic.Int64
+	mtx        sync.Mutex
+	validators map[uint64]*validator
+}
+
+// parent returns the validator running a validator method
+// on the current goroutine and the ID of the goroutine.
+func (a *validatorsByGoroutine) parent() (*validator, uint64) {
+	if a.count.Load() == 0 {
+		// Don't get the goroutine ID if no methods are running
+		return nil, 0
+	}
+	goroutine := goroutineID()
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return a.validators[goroutine], goroutine
+}
+
+func (a *validatorsByGoroutine) set(goroutine uint64, vd *validator) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if vd == nil {
+		delete(a.validators, goroutine)
+	} else {
+		if a.validators == nil {
+			a.validators = make(map[uint64]*validator)
+		}
+		a.validators[goroutine] = vd
+	}
+	a.count.Store(int64(len(a.validators)))
+}
+
+// inValidatorMethodOf returns if a Validate or ValidateContext method
+// of the type t or its pointer type is running on the current goroutine.
+func inValidatorMethodOf(t reflect.Type) bool {
+	var methods []string
+	for _, typ := range []reflect.Type{t, reflect.PointerTo(t)} {
+		for _, name := range []string{"Validate", "ValidateContext"} {
+			if m, ok := typ.MethodByName(name); ok {
+				methods = append(methods, runtime.FuncForPC(m.Func.Pointer()).Name())
+			}
+		}
+	}
+	if len(methods) == 0 {
+		return false
+	}
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(3, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if slices.Contains(methods, frame.Function) {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
+
+// goroutineID returns the ID of the current goroutine
+// parsed from the header of its stack trace.
+func goroutineID() uint64 {
+	var buf [64]byte
+	header := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(header, ' '); i > 0 {
+		header = header[:i]
+	}
+	id, _ := strconv.ParseUint(string(header), 10, 64)
+	return id
+}
+
+// appendError appends a FieldError for the field if err is not nil.
+func (vd *validator) appendError(fieldErrors []FieldError, f ValidationField, err error) []FieldError {
+	if err == nil {
+		return fieldErrors
+	}
+	vd.failed = true
+	return append(fieldErrors, newFieldError(f, err))
+}
+
+// stopped returns true if the validation should not continue
+// because the context is done or because of an error with stopAtFirstError.
+func (vd *validator) stopped() bool {
+	return vd.failed && vd.stopAtFirstError || vd.ctx != nil && vd.ctx.Err() != nil
 }
 
 // ValidateStructFields validates all exported fields of a struct using a custom validation function.
@@ -176,13 +738,26 @@ func (f FieldError) Error() string {
 //   - validateFunc: Function that validates a value and returns an error if invalid
 //   - st: The struct to validate (can be a struct, pointer to struct, or reflect.Value)
 //   - namePrefix: A prefix to add to all field names in errors
-//   - nameTag: The struct tag key to use for field names (e.g., "json"). If empty, uses Go field name
-//   - namesToValidate: Optional list of specific field names to validate. If empty, validates all fields
+//   - nameTag: The struct tag key to use for field names (e.g., "json") or a *NameResolver. If empty, uses Go field name
+//   - namesToValidate: Optional list of specific field names or patterns to validate, see FieldSelector.
+//     If empty, validates all fields
 //
 // Behavior:
-//   - Anonymous embedded structs are flattened
-//   - Named sub-structs are validated recursively
-//   - Array and slice elements are validated individually
+//   - Anonymous embedded structs are flattened like with FlatExportedStructFields,
+//     the fields of nil embedded struct pointers are skipped
+//   - Named sub-structs and non nil pointers to structs are validated recursively,
+//     pointers to structs that are already validated on the current path
+//     because of a pointer cycle are skipped
+//   - Array and slice elements are validated individually,
+//     struct elements recursively with the element as prefix (e.g., "Addresses[2].Street")
+//     and nested slices with multiple indices (e.g., "Matrix[1][3]")
+//   - Map keys and values are validated individually in sorted key order,
+//     errors of values are reported with the entry path (e.g., `Labels["env"]`)
+//     and errors of keys with the key in braces (e.g., `Labels{"env"}`)
+//   - Struct values of maps are validated recursively with the entry as prefix (e.g., `Addrs["home"].Street`)
+//   - The methods of the Validator and ContextValidator interfaces are called
+//     for the struct, its fields, and their elements in addition to validateFunc,
+//     pointer receiver methods only if the values are addressable
 //   - Returns a slice of FieldError for all fields that failed validation
 //
 // Example:
@@ -202,49 +777,167 @@ func (f FieldError) Error() string {
 //	user := User{Name: "", Email: "test@example.com"}
 //	errors := reflection.ValidateStructFields(validateNotEmpty, user, "", "json")
 //	// errors: [FieldError{FieldName: "name", FieldError: errors.New("cannot be empty")}]
-func ValidateStructFields(validateFunc func(any) error, st any, namePrefix, nameTag string, namesToValidate ...string) (fieldErrors []FieldError) {
+//
+// Use ValidateStructFieldsWithOptions with ValidateOptions.NestEmbedded
+// to report the fields of embedded structs with the name of the embedded struct as prefix.
+func ValidateStructFields[N NameSource](validateFunc func(any) error, st any, namePrefix string, nameTag N, namesToValidate ...string) (fieldErrors []FieldError) {
+	return ValidateStructFieldsWithOptions(validateFunc, st, ValidateOptions{
+		NamePrefix:      namePrefix,
+		NameResolver:    nameResolverOf(nameTag),
+		NamesToValidate: namesToValidate,
+	})
+}
+
+// ValidateStructFieldsWithOptions validates all exported fields of a struct
+// using a custom validation function like ValidateStructFields
+// using the passed options.
+//
+// If opts.RulesTag is set, then the rules of that struct tag
+// are checked for every field before the validation function is called.
+// The validation function can be nil to only check rules.
+//
+// Example:
+//
+//	type Base struct {
+//	    ID string `json:"id"`
+//	}
+//	type User struct {
+//	    Base
+//	    Name string `json:"name"`
+//	}
+//
+//	errors := reflection.ValidateStructFieldsWithOptions(validateNotEmpty, User{}, reflection.ValidateOptions{
+//	    NameTag:      "json",
+//	    NestEmbedded: true,
+//	})
+//	// error field names: ["Base.id", "name"]
+func ValidateStructFieldsWithOptions(validateFunc func(any) error, st any, opts ValidateOptions) (fieldErrors []FieldError) {
 	v, t := DerefValueAndType(st)
 	if t.Kind() != reflect.Struct {
 		panic(fmt.Errorf("%T is not a struct or pointer to a struct", st))
 	}
+	return newValidator(validateFunc, &opts).validateStruct(v, opts.NamePrefix)
+}
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		if !field.IsExported() {
-			continue
+// validateStruct validates the root struct v and its fields
+// with namePrefix using a copy of the validator,
+// so that a validator can be reused for multiple structs.
+func (vd validator) validateStruct(v reflect.Value, namePrefix string) []FieldError {
+	vd.root = v
+	vd.namePrefix = namePrefix
+	vd.visiting = nil
+	vd.reported = nil
+	vd.failed = false
+	vd.parent, vd.goroutine = nil, 0
+	var fieldErrors []FieldError
+	if vd.validatorMethods {
+		vd.parent, vd.goroutine = activeValidators.parent()
+		// Without a parent the struct can still be validated
+		// from its own method called directly
+		if vd.parent != nil || !inValidatorMethodOf(v.Type()) {
+			fieldErrors = vd.appendValidatorErrors(nil, vd.rootField(namePrefix))
+		}
+	}
+	return vd.appendStructFieldErrors(fieldErrors, v, namePrefix, namePrefix)
+}
+
+func (vd *validator) appendStructFieldErrors(fieldErrors []FieldError, v reflect.Value, namePrefix, goPrefix string) []FieldError {
+	for f := range vd.structFields(v, namePrefix, goPrefix) {
+		if vd.stopped() {
+			break
 		}
-		fieldName, ext := getFieldName(field, namePrefix, nameTag)
-		if ignoreField(namesToValidate, fieldName, ext) {
+		if !f.Value.IsValid() {
+			// Field of a nil embedded struct pointer
 			continue
 		}
+		if vd.rulesTag != "" && !f.traverseOnly {
+			fieldErrors = vd.appendError(fieldErrors, f, validateRules(f, vd.rulesTag))
+		}
+		if vd.validateField != nil && !f.traverseOnly && !vd.stopped() {
+			fieldErrors = vd.appendError(fieldErrors, f, vd.validateField(f))
+		}
+		fieldErrors = vd.appendFieldErrors(fieldErrors, f)
+	}
+	return fieldErrors
+}
 
-		fieldVal := v.Field(i)
+// appendFieldErrors validates f.Value and appends a FieldError with f.Name
+// in case of an error, then validates the fields of a struct
+// or a non nil pointer to a struct,
+// the elements of a slice or array, or the entries of a map.
+// Fields that are only traversed for selected nested fields
+// and their elements are not validated themselves.
+func (vd *validator) appendFieldErrors(fieldErrors []FieldError, f ValidationField) []FieldError {
+	if !f.traverseOnly {
+		fieldErrors = vd.appendError(fieldErrors, f, vd.validate(f))
+		if !vd.onPath(f.Value) {
+			// The method of a struct on the path is already called
+			fieldErrors = vd.appendValidatorErrors(fieldErrors, f)
+		}
+	}
 
-		err := validate(validateFunc, fieldVal)
-		if err != nil {
-			fieldErrors = append(fieldErrors, FieldError{fieldName, err})
+	switch v := f.Value; v.Kind() {
+	case reflect.Struct:
+		fieldErrors = vd.appendStructFieldErrors(fieldErrors, v, f.Name+".", f.GoName+".")
+
+	case reflect.Ptr:
+		if !v.IsNil() && v.Elem().Kind() == reflect.Struct && vd.enterPointer(v) {
+			fieldErrors = vd.appendStructFieldErrors(fieldErrors, v.Elem(), f.Name+".", f.GoName+".")
+			vd.leavePointer(v)
 		}
 
-		switch kind := fieldVal.Kind(); kind {
-		case reflect.Struct:
-			if fieldVal.CanAddr() {
-				// Use pointer if possible to avoid copy of struct
-				fieldVal = fieldVal.Addr()
-			}
-			fieldErrors = append(fieldErrors, ValidateStructFields(validateFunc, fieldVal.Interface(), fieldName+".", nameTag, namesToValidate...)...)
+	case reflect.Slice, reflect.Array:
+		for j := 0; j < v.Len() && !vd.stopped(); j++ {
+			fieldErrors = vd.appendFieldErrors(fieldErrors, f.elem(IndexSegment(j).String(), v.Index(j)))
+		}
 
-		case reflect.Slice, reflect.Array:
-			for j := 0; j < fieldVal.Len(); j++ {
-				err := validate(validateFunc, fieldVal.Index(j))
-				if err != nil {
-					fieldErrors = append(fieldErrors, FieldError{fmt.Sprintf("%s[%d]", fieldName, j), err})
-				}
+	case reflect.Map:
+		for _, key := range sortedMapKeys(v) {
+			if vd.stopped() {
+				break
 			}
-
-		case reflect.Map:
-			panic("TODO")
+			if !f.traverseOnly {
+				keyField := f.elem(MapKeySegment(key).String(), key)
+				fieldErrors = vd.appendError(fieldErrors, keyField, vd.validate(keyField))
+			}
+			fieldErrors = vd.appendFieldErrors(fieldErrors, f.elem(KeySegment(key).String(), v.MapIndex(key)))
 		}
 	}
-
 	return fieldErrors
 }
+
+// ValidateStructFieldsContext validates all exported fields of a struct
+// like ValidateStructFieldsWithOptions, but with a validation function
+// that gets a context and a ValidationField with the metadata of the field
+// like its name, reflect.StructField, and parsed tag.
+//
+// The validation stops when ctx is done,
+// in that case the FieldErrors found so far
+// are returned together with the error of the context.
+// Set opts.StopAtFirstError to stop after the first FieldError.
+//
+// Example:
+//
+//	fieldErrors, err := reflection.ValidateStructFieldsContext(ctx,
+//	    func(ctx context.Context, field reflection.ValidationField) error {
+//	        if field.Tag.HasOption("unique") {
+//	            return checkUnique(ctx, field.Name, field.Value.Interface())
+//	        }
+//	        return nil
+//	    },
+//	    user,
+//	    reflection.ValidateOptions{NameTag: "db", StopAtFirstError: true},
+//	)
+func ValidateStructFieldsContext(ctx context.Context, validateFunc func(ctx context.Context, field ValidationField) error, st any, opts ValidateOptions) (fieldErrors []FieldError, err error) {
+	v, t := DerefValueAndType(st)
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("%T is not a struct or pointer to a struct", st))
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	vd := newValidator(nil, &opts)
+	vd.ctx = ctx
+	vd.validateContextFunc = validateFunc
+	return vd.validateStruct(v, opts.NamePrefix), ctx.Err()
+}
@@ -1,9 +1,15 @@
 package reflection
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestZeroValueExportedStructFieldNames(t *testing.T) {
@@ -77,3 +83,749 @@ func TestZeroValueExportedStructFieldNames(t *testing.T) {
 	t.Log(zeroNames)
 	assert.ElementsMatch(t, expectedWithIgnore, zeroNames)
 }
+
+func TestZeroValueExportedStructFieldNamesMaps(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+		City   string `json:"city"`
+	}
+	type Key struct {
+		A, B int
+	}
+	type Struct struct {
+		Attrs     map[string]string             `json:"attrs"`
+		AttrsNil  map[string]string             `json:"attrsNil"`
+		Counts    map[int]int                   `json:"counts"`
+		Addresses map[string]Address            `json:"addresses"`
+		AddrPtrs  map[string]*Address           `json:"addrPtrs"`
+		Nested    map[string]map[string]float64 `json:"nested"`
+		Structs   map[Key]bool                  `json:"structs"`
+		Any       map[string]any                `json:"any"`
+	}
+
+	st := Struct{
+		Attrs:  map[string]string{"b": "", "a": "", "c": "value", "quo\"te": ""},
+		Counts: map[int]int{10: 0, 2: 0, -1: 1},
+		Addresses: map[string]Address{
+			"work": {Street: "Main St"},
+			"home": {City: "Springfield"},
+		},
+		AddrPtrs: map[string]*Address{"nil": nil, "set": {Street: "Street", City: "City"}},
+		Nested: map[string]map[string]float64{
+			"y": {"b": 0, "a": 1},
+			"x": nil,
+		},
+		Structs: map[Key]bool{{2, 1}: false, {1, 2}: false},
+		Any:     map[string]any{"nil": nil, "zero": 0, "one": 1},
+	}
+
+	expected := []string{
+		`attrs["a"]`,
+		`attrs["b"]`,
+		`attrs["quo\"te"]`,
+		"attrsNil",
+		"counts[2]",
+		"counts[10]",
+		`addresses["home"].street`,
+		`addresses["work"].city`,
+		`addrPtrs["nil"]`,
+		`nested["x"]`,
+		`nested["y"]["b"]`,
+		"structs[{1 2}]",
+		"structs[{2 1}]",
+		`any["nil"]`,
+		`any["zero"]`,
+	}
+
+	// Repeat to check the deterministic order
+	for range 10 {
+		zeroNames := ZeroValueExportedStructFieldNames(st, "", "json")
+		assert.Equal(t, expected, zeroNames)
+	}
+
+	for _, name := range expected {
+		if name == "attrsNil" || strings.HasPrefix(name, "structs") {
+			// Nil maps have no entries and struct keys can't be parsed
+			continue
+		}
+		_, err := LookupPath(st, name, "json")
+		assert.NoError(t, err, "path of zero name %s", name)
+	}
+}
+
+func TestValidateStructFieldsMaps(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+	}
+	type Struct struct {
+		Labels    map[string]string              `json:"labels"`
+		Counts    map[int]int                    `json:"counts"`
+		Addresses map[string]Address             `json:"addresses"`
+		AddrPtrs  map[string]*Address            `json:"addrPtrs"`
+		Nested    map[string]map[string]string   `json:"nested"`
+		Nil       map[string]string              `json:"nil"`
+		Slices    map[string][]string            `json:"slices"`
+		Ignored   map[string]map[string]struct{} `json:"-"`
+	}
+
+	errEmpty := errors.New("empty")
+	errNegative := errors.New("negative")
+	validateFunc := func(v any) error {
+		switch x := v.(type) {
+		case string:
+			if x == "" {
+				return errEmpty
+			}
+		case int:
+			if x < 0 {
+				return errNegative
+			}
+		}
+		return nil
+	}
+
+	st := Struct{
+		Labels: map[string]string{"env": "", "": "value", "app": "name"},
+		Counts: map[int]int{-1: 1, 2: -2},
+		Addresses: map[string]Address{
+			"work": {Street: ""},
+			"home": {Street: "Main St"},
+		},
+		AddrPtrs: map[string]*Address{"nil": nil, "set": {}},
+		Nested:   map[string]map[string]string{"x": {"y": ""}},
+		Slices:   map[string][]string{"s": {"a", ""}},
+		Ignored:  map[string]map[string]struct{}{"": nil},
+	}
+
+	expected := []nameError{
+		{`labels{""}`, errEmpty},
+		{`labels["env"]`, errEmpty},
+		{"counts{-1}", errNegative},
+		{"counts[2]", errNegative},
+		{`addresses["work"].street`, errEmpty},
+		{`addrPtrs["set"].street`, errEmpty},
+		{`nested["x"]["y"]`, errEmpty},
+		{`slices["s"][1]`, errEmpty},
+	}
+
+	// Repeat to check the deterministic order
+	for range 10 {
+		assert.Equal(t, expected, nameErrors(ValidateStructFields(validateFunc, st, "", "json")))
+	}
+
+	for _, fieldErr := range expected {
+		_, err := LookupPath(st, fieldErr.Name, "json")
+		assert.NoError(t, err, "path of field error %s", fieldErr.Name)
+	}
+}
+
+func TestValidateStructFieldsSliceElements(t *testing.T) {
+	type Address struct {
+		Street string
+		City   string
+	}
+	type Struct struct {
+		Addresses []Address
+		AddrPtrs  []*Address
+		Array     [2]Address
+		Matrix    [][]int
+		Names     []string
+	}
+
+	st := Struct{
+		Addresses: []Address{{"Main St", "City"}, {"", "City"}, {"Street", ""}},
+		AddrPtrs:  []*Address{nil, {Street: "Street"}},
+		Array:     [2]Address{{"Street", "City"}, {}},
+		Matrix:    [][]int{{1}, {1, 2, 3, 0}, nil},
+		Names:     []string{"", "Name"},
+	}
+
+	zeroNames := ZeroValueExportedStructFieldNames(st, "", "")
+	assert.Equal(t, []string{
+		"Addresses[1].Street",
+		"Addresses[2].City",
+		"AddrPtrs[0]",
+		"AddrPtrs[1].City",
+		"Array[1].Street",
+		"Array[1].City",
+		"Matrix[1][3]",
+		"Matrix[2]",
+		"Names[0]",
+	}, zeroNames)
+
+	errZero := errors.New("zero")
+	validateFunc := func(v any) error {
+		switch v.(type) {
+		case string, int:
+			if IsZero(v) {
+				return errZero
+			}
+		}
+		return nil
+	}
+	var errorNames []string
+	for _, fieldErr := range ValidateStructFields(validateFunc, &st, "", "") {
+		assert.Equal(t, errZero, fieldErr.FieldError)
+		errorNames = append(errorNames, fieldErr.FieldName)
+	}
+	assert.Equal(t, []string{
+		"Addresses[1].Street",
+		"Addresses[2].City",
+		"AddrPtrs[1].City",
+		"Array[1].Street",
+		"Array[1].City",
+		"Matrix[1][3]",
+		"Names[0]",
+	}, errorNames)
+}
+
+func TestValidateStructFieldsEmbedded(t *testing.T) {
+	type Base struct {
+		ID string `json:"id"`
+	}
+	type Audit struct {
+		CreatedBy string `json:"createdBy"`
+	}
+	type Named struct {
+		Name string `json:"name"`
+	}
+	type Struct struct {
+		Base
+		*Audit
+		Named `json:"named"`
+		Title string `json:"title"`
+	}
+
+	st := Struct{}
+	assert.Equal(t,
+		[]string{"id", "createdBy", "named.name", "title"},
+		ZeroValueExportedStructFieldNames(st, "", "json"),
+	)
+	assert.Equal(t,
+		[]string{"Base.id", "Audit", "named.name", "title"},
+		ZeroValueExportedStructFieldNamesWithOptions(st, ValidateOptions{NameTag: "json", NestEmbedded: true}),
+	)
+
+	errEmpty := errors.New("empty")
+	validateFunc := func(v any) error {
+		if s, ok := v.(string); ok && s == "" {
+			return errEmpty
+		}
+		return nil
+	}
+	fieldNames := func(fieldErrors []FieldError) (names []string) {
+		for _, fieldErr := range fieldErrors {
+			names = append(names, fieldErr.FieldName)
+		}
+		return names
+	}
+
+	// Fields of nil embedded struct pointers are not validated
+	assert.Equal(t,
+		[]string{"prefix.id", "prefix.named.name", "prefix.title"},
+		fieldNames(ValidateStructFields(validateFunc, &st, "prefix.", "json")),
+	)
+	st.Audit = &Audit{}
+	assert.Equal(t,
+		[]string{"id", "createdBy", "named.name", "title"},
+		fieldNames(ValidateStructFields(validateFunc, &st, "", "json")),
+	)
+	assert.Equal(t,
+		[]string{"Base.id", "Audit.createdBy", "named.name", "title"},
+		fieldNames(ValidateStructFieldsWithOptions(validateFunc, &st, ValidateOptions{NameTag: "json", NestEmbedded: true})),
+	)
+	assert.Equal(t,
+		[]string{"ID"},
+		fieldNames(ValidateStructFieldsWithOptions(validateFunc, &st, ValidateOptions{NamesToValidate: []string{"ID"}})),
+	)
+}
+
+func TestValidateStructFieldsContext(t *testing.T) {
+	type Item struct {
+		SKU string `db:"sku,unique"`
+	}
+	type Order struct {
+		ID    string `db:"id,unique"`
+		Note  string `db:"note"`
+		Items []Item `db:"items"`
+	}
+	order := Order{ID: "1", Note: "note", Items: []Item{{"a"}, {"b"}}}
+
+	type call struct {
+		name   string
+		field  string
+		unique bool
+	}
+	var calls []call
+	errTaken := errors.New("already taken")
+	validateFunc := func(ctx context.Context, field ValidationField) error {
+		calls = append(calls, call{field.Name, field.Field.Name, field.Tag.HasOption("unique")})
+		if field.Tag.HasOption("unique") && field.Value.Kind() == reflect.String {
+			return errTaken
+		}
+		return nil
+	}
+
+	fieldErrors, err := ValidateStructFieldsContext(context.Background(), validateFunc, order, ValidateOptions{NameTag: "db"})
+	require.NoError(t, err)
+	assert.Equal(t, []nameError{{"id", errTaken}, {"items[0].sku", errTaken}, {"items[1].sku", errTaken}}, nameErrors(fieldErrors))
+	assert.Equal(t, []call{
+		{"id", "ID", true},
+		{"note", "Note", false},
+		{"items", "Items", false},
+		{"items[0]", "Items", false},
+		{"items[0].sku", "SKU", true},
+		{"items[1]", "Items", false},
+		{"items[1].sku", "SKU", true},
+	}, calls)
+
+	calls = nil
+	fieldErrors, err = ValidateStructFieldsContext(context.Background(), validateFunc, order, ValidateOptions{NameTag: "db", StopAtFirstError: true})
+	require.NoError(t, err)
+	assert.Equal(t, []nameError{{"id", errTaken}}, nameErrors(fieldErrors))
+	assert.Len(t, calls, 1)
+
+	// Cancel the context during the validation
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = nil
+	fieldErrors, err = ValidateStructFieldsContext(ctx, func(ctx context.Context, field ValidationField) error {
+		if field.Name == "note" {
+			cancel()
+		}
+		return validateFunc(ctx, field)
+	}, order, ValidateOptions{NameTag: "db"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []nameError{{"id", errTaken}}, nameErrors(fieldErrors))
+	assert.Len(t, calls, 2)
+
+	fieldErrors, err = ValidateStructFieldsContext(ctx, validateFunc, order, ValidateOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, fieldErrors)
+
+	// StopAtFirstError also works without context
+	zeroErrors := ValidateStructFieldsWithOptions(func(v any) error {
+		if IsZero(v) {
+			return errors.New("zero")
+		}
+		return nil
+	}, Order{}, ValidateOptions{StopAtFirstError: true})
+	assert.Len(t, zeroErrors, 1)
+}
+
+// nameError is a FieldError reduced to the name and error for comparisons
+type nameError struct {
+	Name string
+	Err  error
+}
+
+func nameErrors(fieldErrors []FieldError) []nameError {
+	result := make([]nameError, len(fieldErrors))
+	for i, f := range fieldErrors {
+		result[i] = nameError{f.FieldName, f.FieldError}
+	}
+	return result
+}
+
+type testPeriod struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+func (p testPeriod) Validate() error {
+	if p.To < p.From {
+		return FieldErrors{{FieldName: "to", FieldError: errors.New("must not be before from")}}
+	}
+	return nil
+}
+
+type testEmail string
+
+func (e *testEmail) Validate() error {
+	if !strings.Contains(string(*e), "@") {
+		return errors.New("invalid email")
+	}
+	return nil
+}
+
+type testCtxCheck struct {
+	Name string `json:"name"`
+}
+
+func (c testCtxCheck) ValidateContext(ctx context.Context) error {
+	if ctx.Value(testCtxKey{}) != nil {
+		return errors.New("checked with context")
+	}
+	return errors.New("checked without context")
+}
+
+type testCtxKey struct{}
+
+type testBooking struct {
+	Period  testPeriod     `json:"period"`
+	Periods []testPeriod   `json:"periods"`
+	Email   testEmail      `json:"email"`
+	EmailP  *testEmail     `json:"emailP"`
+	Check   testCtxCheck   `json:"check"`
+	Nested  map[string]any `json:"nested"`
+}
+
+func (b *testBooking) Validate() error {
+	if len(b.Periods) == 0 {
+		return errors.New("no periods")
+	}
+	return nil
+}
+
+type testCategory struct {
+	Name     string          `json:"name"`
+	Parent   *testCategory   `json:"parent"`
+	Children []*testCategory `json:"children"`
+}
+
+func TestValidateStructFieldsPointerCycle(t *testing.T) {
+	root := &testCategory{}
+	child := &testCategory{Name: "child", Parent: root}
+	grandchild := &testCategory{Parent: child}
+	child.Children = []*testCategory{grandchild}
+	root.Children = []*testCategory{child}
+	validateFunc := func(v any) error {
+		if s, ok := v.(string); ok && s == "" {
+			return errors.New("empty")
+		}
+		return nil
+	}
+
+	// The back-pointers to the parents are not followed
+	assert.Equal(t,
+		[]string{"name", "children[0].children[0].name"},
+		FieldErrors(ValidateStructFields(validateFunc, root, "", "json")).Fields(),
+	)
+	// The pointers back to the validated child are not followed
+	assert.Equal(t,
+		[]string{"parent.name", "children[0].name"},
+		FieldErrors(ValidateStructFields(validateFunc, child, "", "json")).Fields(),
+	)
+	assert.Equal(t,
+		[]string{"name", "parent", "children[0].children[0].name", "children[0].children[0].children"},
+		ZeroValueExportedStructFieldNames(root, "", "json"),
+	)
+}
+
+func TestValidateStructFieldsValidatorMethods(t *testing.T) {
+	booking := testBooking{
+		Period:  testPeriod{From: 2, To: 1},
+		Periods: []testPeriod{{From: 1, To: 2}, {From: 5, To: 3}},
+		Email:   "invalid",
+		Check:   testCtxCheck{Name: "x"},
+	}
+	noop := func(any) error { return nil }
+	opts := ValidateOptions{NamePrefix: "booking.", NameTag: "json"}
+
+	fieldErrors := ValidateStructFieldsWithOptions(noop, &booking, opts)
+	var messages []string
+	for _, fieldErr := range fieldErrors {
+		messages = append(messages, fieldErr.Error())
+	}
+	assert.Equal(t, []string{
+		"booking.period.to: must not be before from",
+		"booking.periods[1].to: must not be before from",
+		"booking.email: invalid email",
+		"booking.check: checked without context",
+	}, messages)
+	// The Go path of the prefix is combined with the name of the returned FieldError
+	assert.Equal(t, "booking.Period.to", fieldErrors[0].GoName)
+	assert.Equal(t, "booking.Periods[1].to", fieldErrors[1].GoName)
+
+	// The root struct is validated with the pointer receiver method
+	booking.Periods = nil
+	opts.NamePrefix = ""
+	fieldErrors = ValidateStructFieldsWithOptions(noop, &booking, opts)
+	if assert.NotEmpty(t, fieldErrors) {
+		assert.Equal(t, "", fieldErrors[0].FieldName)
+		assert.Equal(t, "no periods", fieldErrors[0].Error())
+	}
+	// Not addressable, so pointer methods are not found
+	fieldErrors = ValidateStructFieldsWithOptions(noop, booking, opts)
+	assert.Equal(t, "period.to", fieldErrors[0].FieldName)
+	assert.Len(t, fieldErrors, 2)
+
+	ctx := context.WithValue(context.Background(), testCtxKey{}, true)
+	fieldErrors, err := ValidateStructFieldsContext(ctx, nil, &booking, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "check: checked with context", fieldErrors[len(fieldErrors)-1].Error())
+
+	// Validator methods are called automatically
+	assert.Equal(t,
+		[]string{"", "period.to", "email", "check"},
+		FieldErrors(ValidateStructFields(noop, &booking, "", "json")).Fields(),
+	)
+	opts.SkipValidatorMethods = true
+	assert.Empty(t, ValidateStructFieldsWithOptions(noop, &booking, opts))
+}
+
+type testAccount struct {
+	Name  string `json:"name" validate:"required"`
+	Owner struct {
+		Email string `json:"email" validate:"required"`
+	} `json:"owner"`
+}
+
+// Validate validates the fields of the account itself
+func (a *testAccount) Validate() error {
+	return FieldErrors(ValidateStructRules(a, "", "json")).Err()
+}
+
+func TestValidateStructFieldsValidatorMethodFields(t *testing.T) {
+	var account testAccount
+	// The method validating the fields of its own struct doesn't recurse
+	err := account.Validate()
+	require.Error(t, err)
+	assert.Equal(t, []string{"name", "owner.email"}, err.(FieldErrors).Fields())
+
+	// The fields reported by a validator method are not validated twice
+	type Customer struct {
+		Account  testAccount   `json:"account"`
+		Accounts []testAccount `json:"accounts"`
+	}
+	fieldErrors := ValidateStructRules(&Customer{Accounts: make([]testAccount, 1)}, "", "json")
+	assert.Equal(t,
+		[]string{"account.name", "account.owner.email", "accounts[0].name", "accounts[0].owner.email"},
+		FieldErrors(fieldErrors).Fields(),
+	)
+	assert.Len(t, fieldErrors, 4)
+}
+
+type testUser struct {
+	Name   string    `json:"name"`
+	Age    int       `json:"age"`
+	Friend *testUser `json:"friend"`
+}
+
+func (u *testUser) Validate() error {
+	fieldErrors := ValidateStructFields(validateNonEmptyString, u, "", "json")
+	if u.Age < 0 {
+		fieldErrors = append(fieldErrors, FieldError{FieldName: "age", FieldError: errors.New("negative")})
+	}
+	return FieldErrors(fieldErrors).Err()
+}
+
+type testValueUser struct {
+	Name string `json:"name"`
+}
+
+func (u testValueUser) Validate() error {
+	return FieldErrors(ValidateStructFields(validateNonEmptyString, u, "", "json")).Err()
+}
+
+type testPlainUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func (u *testPlainUser) Validate() error {
+	if u.Age < 0 {
+		return errors.New("negative age")
+	}
+	return nil
+}
+
+func validateNonEmptyString(v any) error {
+	if s, ok := v.(string); ok && s == "" {
+		return errors.New("empty")
+	}
+	return nil
+}
+
+func TestValidateStructFieldsValidatorMethodRecursion(t *testing.T) {
+	// The method validating its own fields is not called again recursively
+	// and the fields reported by it are not validated again
+	fieldErrors := ValidateStructFields(validateNonEmptyString, &testUser{Age: -1}, "", "json")
+	assert.Equal(t,
+		[]nameError{{"name", errors.New("empty")}, {"age", errors.New("negative")}},
+		nameErrors(fieldErrors),
+	)
+	// Same when calling the method directly
+	err := (&testUser{Age: -1}).Validate()
+	require.Error(t, err)
+	assert.Equal(t,
+		[]nameError{{"name", errors.New("empty")}, {"age", errors.New("negative")}},
+		nameErrors(err.(FieldErrors)),
+	)
+	err = testValueUser{}.Validate()
+	require.Error(t, err)
+	assert.Len(t, err.(FieldErrors), 1)
+
+	// Same with a value receiver validating a copy
+	type Team struct {
+		Lead    testValueUser   `json:"lead"`
+		Members []testValueUser `json:"members"`
+	}
+	fieldErrors = ValidateStructFields(validateNonEmptyString, Team{Members: make([]testValueUser, 2)}, "", "json")
+	assert.Equal(t, []string{"lead.name", "members[0].name", "members[1].name"}, FieldErrors(fieldErrors).Fields())
+	assert.Len(t, fieldErrors, 3)
+
+	// The methods of values referencing each other terminate
+	// and report every field only once
+	a := &testUser{Name: "a"}
+	b := &testUser{Friend: a}
+	a.Friend = b
+	fieldErrors = ValidateStructFields(validateNonEmptyString, a, "", "json")
+	assert.Equal(t, []nameError{{"friend.name", errors.New("empty")}}, nameErrors(fieldErrors))
+
+	// Nested fields are still validated
+	// if the method doesn't return FieldErrors for them
+	type Group struct {
+		Users []testPlainUser `json:"users"`
+	}
+	fieldErrors = ValidateStructFields(validateNonEmptyString, &Group{Users: []testPlainUser{{Age: -1}}}, "", "json")
+	assert.Equal(t,
+		[]nameError{{"users[0]", errors.New("negative age")}, {"users[0].name", errors.New("empty")}},
+		nameErrors(fieldErrors),
+	)
+
+	// Validating the same value concurrently is not a recursion
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fieldErrors := ValidateStructFields(validateNonEmptyString, a, "", "json")
+			assert.Len(t, fieldErrors, 1)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestValidateStructFieldsSelector(t *testing.T) {
+	type Address struct {
+		Street   string `json:"street"`
+		City     string `json:"city"`
+		Internal string `json:"internal"`
+	}
+	type Person struct {
+		FirstName string    `json:"first-name"`
+		LastName  string    `json:"last-name" readonly:"true"`
+		Address   Address   `json:"address"`
+		Others    []Address `json:"others"`
+		Tags      []string  `json:"tags"`
+	}
+	person := Person{Others: []Address{{}}, Tags: []string{""}}
+	errEmpty := errors.New("empty")
+	validateFunc := func(v any) error {
+		if s, ok := v.(string); ok && s == "" {
+			return errEmpty
+		}
+		return nil
+	}
+	fieldNames := func(opts ValidateOptions) (names []string) {
+		opts.NameTag = "json"
+		for _, fieldErr := range ValidateStructFieldsWithOptions(validateFunc, person, opts) {
+			names = append(names, fieldErr.FieldName)
+		}
+		return names
+	}
+
+	// Tag names with hyphens are not ignored
+	assert.Equal(t,
+		[]string{"first-name", "last-name", "address.street", "address.city", "address.internal", "others[0].street", "others[0].city", "others[0].internal", "tags[0]"},
+		fieldNames(ValidateOptions{}),
+	)
+	// Nested fields are selected without their parents
+	assert.Equal(t,
+		[]string{"first-name", "address.city"},
+		fieldNames(ValidateOptions{NamesToValidate: []string{"first-name", "address.city"}}),
+	)
+	assert.Equal(t,
+		[]string{"address.street", "address.city", "others[0].street", "tags[0]"},
+		fieldNames(ValidateOptions{Fields: FieldSelector{
+			Include: []string{"address.*", "others.street", "tags"},
+			Exclude: []string{"address.int*"},
+		}}),
+	)
+	assert.Equal(t,
+		[]string{"address.street", "others[0].street"},
+		fieldNames(ValidateOptions{Fields: FieldSelector{Include: []string{"**.street"}}}),
+	)
+	assert.Equal(t,
+		[]string{"first-name", "tags[0]"},
+		fieldNames(ValidateOptions{Fields: FieldSelector{
+			Exclude: []string{"address", "others"},
+			Filter: func(path string, field reflect.StructField) bool {
+				return field.Tag.Get("readonly") == ""
+			},
+		}}),
+	)
+	// Patterns are matched against names with prefix and without row index
+	assert.Equal(t,
+		[]string{"[0].address.city"},
+		FieldErrors(ValidateStructSlice(validateFunc, []Person{person}, 1, ValidateOptions{
+			NameTag: "json",
+			Fields:  FieldSelector{Include: []string{"address.city"}},
+		})).Fields(),
+	)
+
+	assert.Equal(t,
+		[]string{"first-name", "address.street"},
+		ZeroValueExportedStructFieldNamesWithOptions(person, ValidateOptions{
+			NameTag: "json",
+			Fields:  FieldSelector{Include: []string{"first-name", "address.street", "others.missing"}},
+		}),
+	)
+	assert.Panics(t, func() {
+		ZeroValueExportedStructFieldNamesWithOptions(person, ValidateOptions{Fields: FieldSelector{Include: []string{"address..city"}}})
+	})
+}
+
+func TestValidateStructFieldsGroups(t *testing.T) {
+	type Address struct {
+		Street string `json:"street" validate:"required" groups:"create"`
+		City   string `json:"city" validate:"required"`
+	}
+	type User struct {
+		ID       string  `json:"id" validate:"required" groups:"update, admin"`
+		Email    string  `json:"email" validate:"required" groups:"create,update"`
+		Role     string  `json:"role" validate:"required" groups:"admin"`
+		Name     string  `json:"name" validate:"required"`
+		Address  Address `json:"address"`
+		Internal string  `json:"internal" validate:"required" groups:""`
+	}
+	fieldNames := func(groups ...string) []string {
+		return FieldErrors(ValidateStructFieldsWithOptions(nil, User{}, ValidateOptions{
+			NameTag:  "json",
+			RulesTag: "validate",
+			Groups:   groups,
+		})).Fields()
+	}
+
+	assert.Equal(t, []string{"id", "email", "role", "name", "address.street", "address.city", "internal"}, fieldNames())
+	assert.Equal(t, []string{"email", "address.street"}, fieldNames("create"))
+	assert.Equal(t, []string{"id", "email"}, fieldNames("update"))
+	assert.Equal(t, []string{"id", "role", "name", "address.city"}, fieldNames("admin", DefaultValidationGroup))
+
+	assert.Equal(t,
+		[]string{"id", "email"},
+		ZeroValueExportedStructFieldNamesWithOptions(User{}, ValidateOptions{NameTag: "json", Groups: []string{"update"}}),
+	)
+
+	idField, _ := reflect.TypeFor[User]().FieldByName("ID")
+	assert.Equal(t, []string{"update", "admin"}, FieldValidationGroups(idField))
+	nameField, _ := reflect.TypeFor[User]().FieldByName("Name")
+	assert.Equal(t, []string{DefaultValidationGroup}, FieldValidationGroups(nameField))
+	internalField, _ := reflect.TypeFor[User]().FieldByName("Internal")
+	assert.Empty(t, FieldValidationGroups(internalField))
+	assert.Equal(t, []string{"update", "admin"}, getStructTypeInfo(reflect.TypeFor[User]()).fields[0].Groups)
+
+	// Flattened embedded fields keep their groups
+	type Admin struct {
+		User
+		Level int `json:"level" validate:"required" groups:"admin"`
+	}
+	assert.Equal(t,
+		[]string{"id", "role", "level"},
+		FieldErrors(ValidateStructFieldsWithOptions(nil, Admin{}, ValidateOptions{
+			NameTag:  "json",
+			RulesTag: "validate",
+			Groups:   []string{"admin"},
+		})).Fields(),
+	)
+}